@@ -0,0 +1,102 @@
+package yeelight
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetAlias sets the local alias of light id, SSDP names are often
+// empty or duplicated. Aliases are unique, an empty alias removes it
+func (ls *Lights) SetAlias(id, alias string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if alias == "" {
+		delete(ls.aliases, id)
+		return nil
+	}
+	for other, a := range ls.aliases {
+		if a == alias && other != id {
+			return fmt.Errorf("%w: alias %q used by %s", errInvalidParam, alias, other)
+		}
+	}
+	ls.aliases[id] = alias
+	return nil
+}
+
+// Alias returns the alias of light id, "" if none
+func (ls *Lights) Alias(id string) string {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.aliases[id]
+}
+
+// Tag adds tags to light id
+func (ls *Lights) Tag(id string, tags ...string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.tags[id] == nil {
+		ls.tags[id] = make(map[string]bool)
+	}
+	for _, t := range tags {
+		ls.tags[id][t] = true
+	}
+}
+
+// Untag removes tags from light id
+func (ls *Lights) Untag(id string, tags ...string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	for _, t := range tags {
+		delete(ls.tags[id], t)
+	}
+	if len(ls.tags[id]) == 0 {
+		delete(ls.tags, id)
+	}
+}
+
+// Tags returns the sorted tags of light id
+func (ls *Lights) Tags(id string) []string {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return sortedKeys(ls.tags[id])
+}
+
+// ByTag returns the lights tagged with tag sorted by ID
+func (ls *Lights) ByTag(tag string) []*Light {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	var lights []*Light
+	for id, tags := range ls.tags {
+		if l, ok := ls.m[id].(*Light); ok && tags[tag] {
+			lights = append(lights, l)
+		}
+	}
+	sort.Slice(lights, func(i, j int) bool { return lights[i].ID < lights[j].ID })
+	return lights
+}
+
+// Lookup returns the light with ID or alias ref, or the only
+// light named ref. It returns nil if none or several match
+func (ls *Lights) Lookup(ref string) *Light {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	if l, ok := ls.m[ref].(*Light); ok {
+		return l
+	}
+	for id, a := range ls.aliases {
+		if a == ref {
+			l, _ := ls.m[id].(*Light)
+			return l
+		}
+	}
+	var found *Light
+	for _, d := range ls.m {
+		if l, ok := d.(*Light); ok && l.Name != "" && l.Name == ref {
+			if found != nil {
+				return nil
+			}
+			found = l
+		}
+	}
+	return found
+}
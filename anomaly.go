@@ -0,0 +1,50 @@
+package yeelight
+
+import (
+	"context"
+	"time"
+)
+
+// Anomaly is a protocol message the light did not expect, either
+// a result to an unknown request or a notification other than props
+type Anomaly struct {
+	Result       *Result       `json:"result,omitempty"`
+	Notification *Notification `json:"notification,omitempty"`
+	At           time.Time     `json:"at"`
+}
+
+// OnAnomaly registers f to be called on every protocol anomaly,
+// f must not block as it runs on light's goroutines
+func (l *Light) OnAnomaly(f func(Anomaly)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.anomalies = append(l.anomalies, f)
+}
+
+// Anomalies returns a channel receiving light's protocol anomalies
+// until ctx is done, then it is closed. Anomalies are dropped if
+// more than buffer are pending
+func (l *Light) Anomalies(ctx context.Context, buffer int) <-chan Anomaly {
+	c := make(chan Anomaly, buffer)
+	s := l.subscribe(ctx, "anomalies", func() int { return len(c) }, func() { close(c) })
+	l.OnAnomaly(func(a Anomaly) {
+		s.send(func() {
+			select {
+			case c <- a:
+			default:
+			}
+		})
+	})
+	return c
+}
+
+// anomaly calls the anomaly handlers with a
+func (l *Light) anomaly(a Anomaly) {
+	l.mu.Lock()
+	handlers := l.anomalies
+	l.mu.Unlock()
+	a.At = l.now()
+	for _, f := range handlers {
+		f(a)
+	}
+}
@@ -0,0 +1,154 @@
+package yeelight
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// AudioFrame is a measurement of the audio being played
+type AudioFrame struct {
+	// Level is the loudness, usually the RMS of the samples, 0-1
+	Level float64
+	// Bands are optional FFT magnitudes from low to high frequencies,
+	// when given the hue follows the dominant frequencies
+	Bands []float64
+}
+
+// AudioConfig tunes how audio drives a light, zero values use defaults
+type AudioConfig struct {
+	// Sensitivity multiplies levels before mapping them to brightness, 1
+	Sensitivity float64
+	// Smoothing is the weight of the previous energy on each frame,
+	// 0-1, higher values give calmer changes, 0.6
+	Smoothing float64
+	// BeatThreshold is how many times louder than the average a
+	// frame must be to be a beat, 1.5
+	BeatThreshold float64
+	// HueStep is the hue advance on each beat in degrees, 30
+	HueStep int
+	// MinBright and MaxBright bound the brightness, 1 and 100
+	MinBright, MaxBright int
+	// MaxRate bounds the updates sent per second, 20
+	MaxRate int
+}
+
+// withDefaults returns c with its zero values replaced by defaults
+func (c AudioConfig) withDefaults() AudioConfig {
+	if c.Sensitivity <= 0 {
+		c.Sensitivity = 1
+	}
+	if c.Smoothing <= 0 || c.Smoothing >= 1 {
+		c.Smoothing = 0.6
+	}
+	if c.BeatThreshold <= 0 {
+		c.BeatThreshold = 1.5
+	}
+	if c.HueStep == 0 {
+		c.HueStep = 30
+	}
+	if c.MinBright <= 0 {
+		c.MinBright = 1
+	}
+	if c.MaxBright <= 0 || c.MaxBright > 100 {
+		c.MaxBright = 100
+	}
+	if c.MaxRate <= 0 {
+		c.MaxRate = 20
+	}
+	return c
+}
+
+// Weight of each frame in the long term average detecting beats
+const audioAverageWeight = 0.05
+
+// audioMapper turns audio frames into colors
+type audioMapper struct {
+	cfg    AudioConfig
+	energy float64
+	avg    float64
+	offset int
+}
+
+// next feeds f returning the color to show
+func (m *audioMapper) next(f AudioFrame) (hue, sat, bright int) {
+	level := f.Level * m.cfg.Sensitivity
+	m.energy = m.cfg.Smoothing*m.energy + (1-m.cfg.Smoothing)*level
+	if m.avg > 0 && level > m.avg*m.cfg.BeatThreshold {
+		m.offset = (m.offset + m.cfg.HueStep) % 360
+	}
+	m.avg = (1-audioAverageWeight)*m.avg + audioAverageWeight*level
+
+	hue = m.offset
+	if c, ok := centroid(f.Bands); ok {
+		// Lows are red, highs violet
+		hue += int(c * 300)
+	}
+	span := float64(m.cfg.MaxBright - m.cfg.MinBright)
+	bright = m.cfg.MinBright + int(math.Round(span*math.Min(m.energy, 1)))
+	return (hue%360 + 360) % 360, 100, bright
+}
+
+// centroid returns the position, 0-1, of the bands' center of mass
+func centroid(bands []float64) (float64, bool) {
+	var sum, weighted float64
+	for i, b := range bands {
+		sum += b
+		weighted += float64(i) * b
+	}
+	if sum <= 0 || len(bands) < 2 {
+		return 0, false
+	}
+	return weighted / sum / float64(len(bands)-1), true
+}
+
+// AudioReactive drives light with frames until ctx is done or frames
+// is closed, brightness follows the energy and the hue changes on
+// beats and with the spectrum. Updates are sent over music mode,
+// started if needed and stopped on return if it was started here
+func (l *Light) AudioReactive(ctx context.Context, frames <-chan AudioFrame, cfg AudioConfig) error {
+	m := &audioMapper{cfg: cfg.withDefaults()}
+	if l.musicConn() == nil {
+		if err := l.StartMusic(""); err != nil {
+			return err
+		}
+		defer l.StopMusic()
+	}
+	tick := l.clock().NewTicker(time.Second / time.Duration(m.cfg.MaxRate))
+	defer tick.Stop()
+	var cur [3]int
+	last := [3]int{-1, -1, -1}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case f, ok := <-frames:
+			if !ok {
+				return nil
+			}
+			cur[0], cur[1], cur[2] = m.next(f)
+		case <-tick.C():
+			// Frames are coalesced, only the last one is shown
+			if cur == last || cur[2] == 0 {
+				continue
+			}
+			if err := l.sendHSV(cur[0], cur[1], cur[2]); err != nil {
+				return err
+			}
+			last = cur
+		}
+	}
+}
+
+// sendHSV sets color and brightness at once, with a scene if supported
+func (l *Light) sendHSV(hue, sat, bright int) error {
+	if l.Support["set_scene"] {
+		_, err := l.SendCommand("set_scene", "hsv", hue, sat, bright)
+		return err
+	}
+	if _, err := l.SendCommand("set_hsv", hue, sat, "sudden", 0); err != nil {
+		return err
+	}
+	_, err := l.SendCommand("set_bright", bright, "sudden", 0)
+	return err
+}
@@ -0,0 +1,116 @@
+package yeelight
+
+import (
+	"net"
+	"time"
+)
+
+// How often a light switched to music mode automatically checks
+// whether its command rate subsided
+const autoMusicCheck = 5 * time.Second
+
+// How long a light waits to switch to music mode automatically
+// again after failing, so commands near the quota don't each
+// send another set_music
+const autoMusicBackoff = time.Minute
+
+// WithAutoMusic switches lights supporting it to music mode once
+// they are sent perMinute commands within a minute, before hitting
+// the LAN quota. Commands are routed there transparently until the
+// rate drops below half of perMinute. Zero disables it
+func WithAutoMusic(perMinute int) Option {
+	return func(c *config) {
+		if perMinute >= 0 && perMinute <= commandQuota {
+			c.autoMusic = perMinute
+		}
+	}
+}
+
+// countSent records a command sent for CommandRate
+func (l *Light) countSent() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.recent = append(trimRecent(l.recent, now), now)
+}
+
+// trimRecent drops the times of recent older than a minute
+func trimRecent(recent []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(recent) && now.Sub(recent[i]) >= time.Minute {
+		i++
+	}
+	return recent[i:]
+}
+
+// CommandRate returns how many commands were sent to light
+// within the last minute, in music mode or not
+func (l *Light) CommandRate() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recent = trimRecent(l.recent, l.now())
+	return len(l.recent)
+}
+
+// nearQuota returns true if one more command reaches the rate
+// configured by WithAutoMusic
+func (l *Light) nearQuota() bool {
+	return l.cfg.autoMusic > 0 && l.Support["set_music"] && l.CommandRate()+1 >= l.cfg.autoMusic
+}
+
+// autoStartMusic switches light to music mode until its command
+// rate subsides, it returns false if music mode is not available
+// or failed within autoMusicBackoff
+func (l *Light) autoStartMusic() bool {
+	l.mu.Lock()
+	retry := l.musicRetry
+	l.mu.Unlock()
+	if l.now().Before(retry) {
+		return false
+	}
+	if err := l.StartMusic(""); err != nil {
+		l.log().WithField("error", err).Warnf("Cannot switch to music mode")
+		l.mu.Lock()
+		l.musicRetry = l.now().Add(autoMusicBackoff)
+		l.mu.Unlock()
+		return false
+	}
+	l.mu.Lock()
+	l.musicAuto = true
+	music := l.music
+	l.mu.Unlock()
+	l.log().WithField("rate", l.CommandRate()).Infof("Switched to music mode")
+	go l.autoStopMusic(music)
+	return true
+}
+
+// autoStopMusic leaves music mode once the command rate drops below
+// half the threshold, unless music has been left or replaced already
+func (l *Light) autoStopMusic(music net.Conn) {
+	low := l.cfg.autoMusic / 2
+	if l.cfg.autoMusic == 0 {
+		// Switched by SetRateLimit, quota is the reference
+		low = commandQuota / 2
+	}
+	t := l.clock().NewTicker(autoMusicCheck)
+	defer t.Stop()
+	for range t.C() {
+		l.mu.Lock()
+		current, auto := l.music, l.musicAuto
+		l.mu.Unlock()
+		if current == nil || current != music || !auto {
+			return
+		}
+		if l.CommandRate() >= low {
+			continue
+		}
+		l.mu.Lock()
+		l.musicAuto = false
+		l.mu.Unlock()
+		l.log().Infof("Command rate subsided, leaving music mode")
+		if err := l.StopMusic(); err != nil {
+			l.log().WithField("error", err).Warnf("Cannot leave music mode")
+		}
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package yeelight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Lights commanded at once by batch operations when no limit is given
+const defaultBatchWorkers = 8
+
+// BatchResult is the outcome of a command on one light of a batch
+type BatchResult struct {
+	Light  *Light
+	Result *Result
+	Err    error
+}
+
+// BatchError reports the lights that failed in a batch by ID
+type BatchError struct {
+	Failed map[string]error
+}
+
+// Error implements the error interface
+func (e *BatchError) Error() string {
+	ids := make([]string, 0, len(e.Failed))
+	for id := range e.Failed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	msgs := make([]string, len(ids))
+	for i, id := range ids {
+		msgs[i] = fmt.Sprintf("%s: %s", id, e.Failed[id])
+	}
+	return fmt.Sprintf("%d lights failed: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed lights
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ForEach runs f on lights with at most workers at once, zero uses
+// a default. It returns a *BatchError if f failed for any light
+func ForEach(ctx context.Context, lights []*Light, workers int, f func(ctx context.Context, l *Light) error) error {
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed = make(map[string]error)
+		sem    = make(chan struct{}, workers)
+	)
+	for _, l := range lights {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			failed[l.ID] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(l *Light) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := f(ctx, l); err != nil {
+				mu.Lock()
+				failed[l.ID] = err
+				mu.Unlock()
+			}
+		}(l)
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		return &BatchError{Failed: failed}
+	}
+	return nil
+}
+
+// Broadcast calls method on lights with at most workers at once,
+// the results are in the order of lights
+func Broadcast(ctx context.Context, lights []*Light, workers int, method string, params ...interface{}) ([]BatchResult, error) {
+	results := make([]BatchResult, len(lights))
+	index := make(map[*Light]int, len(lights))
+	for i, l := range lights {
+		index[l] = i
+		results[i].Light = l
+	}
+	err := ForEach(ctx, lights, workers, func(ctx context.Context, l *Light) error {
+		r, err := l.Call(ctx, method, params...)
+		i := index[l]
+		results[i].Result, results[i].Err = r, err
+		return err
+	})
+	return results, err
+}
+
+// ForEach runs f on every light of the collection, see ForEach
+func (ls *Lights) ForEach(ctx context.Context, workers int, f func(ctx context.Context, l *Light) error) error {
+	return ForEach(ctx, ls.list(), workers, f)
+}
+
+// Broadcast calls method on every light of the collection, see Broadcast
+func (ls *Lights) Broadcast(ctx context.Context, workers int, method string, params ...interface{}) ([]BatchResult, error) {
+	return Broadcast(ctx, ls.list(), workers, method, params...)
+}
+
+// ForEach runs f on every light of the group, see ForEach
+func (g *Group) ForEach(ctx context.Context, workers int, f func(ctx context.Context, l *Light) error) error {
+	return ForEach(ctx, g.Lights, workers, f)
+}
+
+// Broadcast calls method on every light of the group, see Broadcast
+func (g *Group) Broadcast(ctx context.Context, workers int, method string, params ...interface{}) ([]BatchResult, error) {
+	return Broadcast(ctx, g.Lights, workers, method, params...)
+}
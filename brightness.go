@@ -0,0 +1,86 @@
+package yeelight
+
+import "math"
+
+// DefaultGamma makes brightness steps look even to the eye
+const DefaultGamma = 2.2
+
+// WithBrightnessCurve applies a perceptual curve to the brightness of
+// commands, so 50% looks half as bright instead of nearly full. The
+// light gets floor + (100-floor) * (b/100)^gamma, floor keeping the
+// dimmest settings visible. A gamma of 1 with a zero floor is linear,
+// the default
+func WithBrightnessCurve(gamma float64, floor int) Option {
+	return func(c *config) {
+		if gamma > 0 && floor >= 0 && floor < 100 {
+			c.gamma = gamma
+			c.brightFloor = floor
+		}
+	}
+}
+
+// curve returns the light's brightness of perceived brightness b
+func (c *config) curve(b int) int {
+	if c.gamma == 0 || (c.gamma == 1 && c.brightFloor == 0) || b <= 0 {
+		return b
+	}
+	v := float64(c.brightFloor) + float64(100-c.brightFloor)*math.Pow(float64(b)/100, c.gamma)
+	return clamp(int(math.Round(v)), 1, 100)
+}
+
+// uncurve is the inverse of curve
+func (c *config) uncurve(b int) int {
+	if c.gamma == 0 || (c.gamma == 1 && c.brightFloor == 0) || b <= 0 {
+		return b
+	}
+	v := float64(b-c.brightFloor) / float64(100-c.brightFloor)
+	if v <= 0 {
+		return 1
+	}
+	return clamp(int(math.Round(100*math.Pow(v, 1/c.gamma))), 1, 100)
+}
+
+// PerceivedBright returns light's brightness on the scale of
+// WithBrightnessCurve, Bright keeps the light's value
+func (l *Light) PerceivedBright() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cfg.uncurve(l.Bright)
+}
+
+// Index of the brightness param of the methods changing it
+var brightParam = map[string]int{
+	"set_bright":    0,
+	"bg_set_bright": 0,
+}
+
+// Index of the brightness param of set_scene classes, class included
+var sceneBrightParam = map[string]int{
+	"color": 2,
+	"hsv":   3,
+	"ct":    2,
+}
+
+// applyCurve maps the brightness of cmd through the brightness curve
+func (l *Light) applyCurve(cmd *Command) {
+	i, ok := brightParam[cmd.Method]
+	if cmd.Method == "set_scene" || cmd.Method == "bg_set_scene" {
+		if len(cmd.Params) > 0 {
+			class, _ := cmd.Params[0].(string)
+			i, ok = sceneBrightParam[class]
+		}
+	}
+	if !ok || i >= len(cmd.Params) {
+		return
+	}
+	b, ok := numParam(cmd.Params[i])
+	if !ok {
+		return
+	}
+	if curved := l.cfg.curve(b); curved != b {
+		// Callers may reuse their params
+		params := append([]interface{}(nil), cmd.Params...)
+		params[i] = curved
+		cmd.Params = params
+	}
+}
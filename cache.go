@@ -0,0 +1,108 @@
+package yeelight
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// cachedProp is a property value and when it was received
+type cachedProp struct {
+	value string
+	at    time.Time
+}
+
+// WithPropCacheTTL sets how long get_prop values are served from
+// cache by GetProperties, zero disables the cache
+func WithPropCacheTTL(ttl time.Duration) Option {
+	return func(c *config) {
+		if ttl >= 0 {
+			c.propCacheTTL = ttl
+		}
+	}
+}
+
+// GetProperties returns the values of props, from the cache if all
+// of them were received within the cache TTL or asking the light
+// otherwise. It needs the light to be listening to get the result
+func (l *Light) GetProperties(props ...string) (map[string]string, error) {
+	if vals, ok := l.cachedProps(props); ok {
+		return vals, nil
+	}
+	return l.FetchProperties(props...)
+}
+
+// FetchProperties asks the light for props bypassing the cache
+func (l *Light) FetchProperties(props ...string) (map[string]string, error) {
+	params := make([]interface{}, len(props))
+	for i, p := range props {
+		params[i] = p
+	}
+	id, err := l.GetProp(params...)
+	if err != nil {
+		return nil, err
+	}
+	r := l.waitResult(id, l.cfg.commandTimeout)
+	if r == nil {
+		return nil, ErrTimeout
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	vals := make(map[string]string, len(props))
+	for i, p := range props {
+		if i < len(r.Result) {
+			vals[p] = propString(r.Result[i])
+		}
+	}
+	l.cacheProps(vals)
+	return vals, nil
+}
+
+// propString formats a decoded JSON property value, numbers
+// are formatted without exponent as lights send them
+func propString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// InvalidateCache discards all cached property values
+func (l *Light) InvalidateCache() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.propCache = nil
+}
+
+func (l *Light) cacheProps(vals map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.propCacheTTL == 0 {
+		return
+	}
+	if l.propCache == nil {
+		l.propCache = make(map[string]cachedProp)
+	}
+	now := l.now()
+	for k, v := range vals {
+		l.propCache[k] = cachedProp{value: v, at: now}
+	}
+}
+
+func (l *Light) cachedProps(props []string) (map[string]string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.propCacheTTL == 0 {
+		return nil, false
+	}
+	vals := make(map[string]string, len(props))
+	for _, p := range props {
+		c, ok := l.propCache[p]
+		if !ok || l.now().Sub(c.at) > l.cfg.propCacheTTL {
+			return nil, false
+		}
+		vals[p] = c.value
+	}
+	return vals, true
+}
@@ -0,0 +1,76 @@
+package yeelight
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAge returns the max-age directive of a Cache-Control header
+func maxAge(cacheControl string) (time.Duration, bool) {
+	for _, d := range strings.Split(cacheControl, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(d), "=")
+		if !ok || strings.ToLower(k) != "max-age" {
+			continue
+		}
+		secs, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	return 0, false
+}
+
+// MaxAge returns the SSDP Cache-Control max-age of light,
+// zero if it did not announce one
+func (l *Light) MaxAge() time.Duration {
+	age, _ := maxAge(l.CacheControl)
+	return age
+}
+
+// DiscoveryExpiresAt returns when light's discovery information
+// becomes stale according to its SSDP Cache-Control max-age,
+// the zero time if the light did not announce one
+func (l *Light) DiscoveryExpiresAt() time.Time {
+	return l.ExpiresAt
+}
+
+// Stale returns true if light's discovery information expired
+// and it has not talked over its connection for max-age either
+func (l *Light) Stale(now time.Time) bool {
+	if l.ExpiresAt.IsZero() || now.Before(l.ExpiresAt) {
+		return false
+	}
+	return now.Sub(time.Unix(l.lastSeen(), 0)) > l.MaxAge()
+}
+
+// expiresAt computes ExpiresAt from when light was discovered
+func (l *Light) expiresAt() time.Time {
+	age, ok := maxAge(l.CacheControl)
+	if !ok || l.discoveredAt.IsZero() {
+		return time.Time{}
+	}
+	return l.discoveredAt.Add(age)
+}
+
+// refreshInterval returns how often light's state is re-verified,
+// half its max-age so it is checked before going stale but never
+// more often than the refresh period. Adaptive refresh halves it for
+// every refresh without notifications in between, down to its minimum
+func (l *Light) refreshInterval() time.Duration {
+	d := l.cfg.refreshPeriod
+	if age, ok := maxAge(l.CacheControl); ok && age/2 > d {
+		d = age / 2
+	}
+	if min := l.cfg.refreshMin; min > 0 {
+		for i := 0; i < l.quietRefreshes && d/2 >= min; i++ {
+			d /= 2
+		}
+	}
+	if j := l.cfg.refreshJitter; j > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * j * float64(d))
+	}
+	return d
+}
@@ -0,0 +1,146 @@
+package yeelight
+
+import "fmt"
+
+// Calibration matches the colors of lights of different models or
+// batches, it is applied transparently to the colors and color
+// temperatures sent to the light
+type Calibration struct {
+	// Multipliers of each RGB channel, zero is taken as 1
+	R float64 `json:"r,omitempty"`
+	G float64 `json:"g,omitempty"`
+	B float64 `json:"b,omitempty"`
+	// CTOffset is added to color temperatures in Kelvin
+	CTOffset int `json:"ct_offset,omitempty"`
+}
+
+// SetCalibration sets light's calibration, nil removes it
+func (l *Light) SetCalibration(c *Calibration) error {
+	if c != nil && (c.R < 0 || c.G < 0 || c.B < 0) {
+		return fmt.Errorf("%w: negative channel multiplier", errInvalidParam)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c != nil {
+		cp := *c
+		c = &cp
+	}
+	l.calibration = c
+	return nil
+}
+
+// Calibration returns a copy of light's calibration, nil if none
+func (l *Light) Calibration() *Calibration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.calibration == nil {
+		return nil
+	}
+	c := *l.calibration
+	return &c
+}
+
+// RGB returns rgb with the channel multipliers applied
+func (c *Calibration) RGB(rgb int) int {
+	channel := func(v int, m float64) int {
+		if m == 0 {
+			return v
+		}
+		return clamp(int(float64(v)*m+0.5), 0, 255)
+	}
+	r := channel(rgb>>16&0xff, c.R)
+	g := channel(rgb>>8&0xff, c.G)
+	b := channel(rgb&0xff, c.B)
+	return r<<16 | g<<8 | b
+}
+
+// CT returns ct with the offset applied within m's range
+func (c *Calibration) CT(ct int, m *Model) int {
+	return m.ClampCT(ct + c.CTOffset)
+}
+
+// InverseRGB returns the color RGB was applied to, as
+// close as the rounding of the multipliers allows
+func (c *Calibration) InverseRGB(rgb int) int {
+	channel := func(v int, m float64) int {
+		if m == 0 {
+			return v
+		}
+		return clamp(int(float64(v)/m+0.5), 0, 255)
+	}
+	r := channel(rgb>>16&0xff, c.R)
+	g := channel(rgb>>8&0xff, c.G)
+	b := channel(rgb&0xff, c.B)
+	return r<<16 | g<<8 | b
+}
+
+// InverseCT returns the color temperature CT was applied to
+func (c *Calibration) InverseCT(ct int, m *Model) int {
+	return m.ClampCT(ct - c.CTOffset)
+}
+
+// NominalRGB returns light's color before its calibration, as
+// given to SetRGB. RGB keeps the light's value
+func (l *Light) NominalRGB() int {
+	l.mu.Lock()
+	rgb, c := l.RGB, l.calibration
+	l.mu.Unlock()
+	if c == nil {
+		return rgb
+	}
+	return c.InverseRGB(rgb)
+}
+
+// NominalCT returns light's color temperature before its
+// calibration, as given to SetTemperature. CT keeps the light's value
+func (l *Light) NominalCT() int {
+	l.mu.Lock()
+	ct, c := l.CT, l.calibration
+	l.mu.Unlock()
+	if c == nil || ct == 0 {
+		return ct
+	}
+	return c.InverseCT(ct, l.ModelSpec())
+}
+
+// calibrate applies light's calibration to the color of cmd
+func (l *Light) calibrate(cmd *Command) {
+	l.mu.Lock()
+	c := l.calibration
+	l.mu.Unlock()
+	if c == nil {
+		return
+	}
+	i, isCT := -1, false
+	switch cmd.Method {
+	case "set_rgb":
+		i = 0
+	case "set_ct_abx":
+		i, isCT = 0, true
+	case "set_scene":
+		if len(cmd.Params) > 0 {
+			switch cmd.Params[0] {
+			case "color":
+				i = 1
+			case "ct":
+				i, isCT = 1, true
+			}
+		}
+	}
+	if i < 0 || i >= len(cmd.Params) {
+		return
+	}
+	v, ok := numParam(cmd.Params[i])
+	if !ok {
+		return
+	}
+	if isCT {
+		v = c.CT(v, l.ModelSpec())
+	} else {
+		v = c.RGB(v)
+	}
+	// Callers may reuse their params
+	params := append([]interface{}(nil), cmd.Params...)
+	params[i] = v
+	cmd.Params = params
+}
@@ -0,0 +1,121 @@
+package yeelight
+
+import "sort"
+
+// Capability is a command a light may support
+type Capability int
+
+// Capabilities announced in the SSDP Support header
+const (
+	CapUnknown Capability = iota
+	CapGetProp
+	CapSetDefault
+	CapSetPower
+	CapToggle
+	CapSetBright
+	CapSetCTAbx
+	CapSetRGB
+	CapSetHSV
+	CapStartCF
+	CapStopCF
+	CapSetScene
+	CapCronAdd
+	CapCronGet
+	CapCronDel
+	CapSetAdjust
+	CapAdjustBright
+	CapAdjustCT
+	CapAdjustColor
+	CapSetMusic
+	CapSetName
+	CapBgSetRGB
+	CapBgSetHSV
+	CapBgSetCTAbx
+	CapBgStartCF
+	CapBgStopCF
+	CapBgSetScene
+	CapBgSetDefault
+	CapBgSetPower
+	CapBgSetBright
+	CapBgSetAdjust
+	CapBgToggle
+	CapDevToggle
+)
+
+var capabilityNames = map[Capability]string{
+	CapGetProp:      "get_prop",
+	CapSetDefault:   "set_default",
+	CapSetPower:     "set_power",
+	CapToggle:       "toggle",
+	CapSetBright:    "set_bright",
+	CapSetCTAbx:     "set_ct_abx",
+	CapSetRGB:       "set_rgb",
+	CapSetHSV:       "set_hsv",
+	CapStartCF:      "start_cf",
+	CapStopCF:       "stop_cf",
+	CapSetScene:     "set_scene",
+	CapCronAdd:      "cron_add",
+	CapCronGet:      "cron_get",
+	CapCronDel:      "cron_del",
+	CapSetAdjust:    "set_adjust",
+	CapAdjustBright: "adjust_bright",
+	CapAdjustCT:     "adjust_ct",
+	CapAdjustColor:  "adjust_color",
+	CapSetMusic:     "set_music",
+	CapSetName:      "set_name",
+	CapBgSetRGB:     "bg_set_rgb",
+	CapBgSetHSV:     "bg_set_hsv",
+	CapBgSetCTAbx:   "bg_set_ct_abx",
+	CapBgStartCF:    "bg_start_cf",
+	CapBgStopCF:     "bg_stop_cf",
+	CapBgSetScene:   "bg_set_scene",
+	CapBgSetDefault: "bg_set_default",
+	CapBgSetPower:   "bg_set_power",
+	CapBgSetBright:  "bg_set_bright",
+	CapBgSetAdjust:  "bg_set_adjust",
+	CapBgToggle:     "bg_toggle",
+	CapDevToggle:    "dev_toggle",
+}
+
+var capabilityByName = func() map[string]Capability {
+	m := make(map[string]Capability, len(capabilityNames))
+	for c, n := range capabilityNames {
+		m[n] = c
+	}
+	return m
+}()
+
+// String returns the protocol method name of the capability
+func (c Capability) String() string {
+	if n, ok := capabilityNames[c]; ok {
+		return n
+	}
+	return "unknown"
+}
+
+// ParseCapability returns the capability of a protocol method name
+func ParseCapability(method string) (Capability, bool) {
+	c, ok := capabilityByName[method]
+	return c, ok
+}
+
+// Supports returns true if light supports capability c
+func (l *Light) Supports(c Capability) bool {
+	return l.Support[c.String()]
+}
+
+// Capabilities returns the known capabilities supported by light
+// sorted by value, methods without a Capability are omitted
+func (l *Light) Capabilities() []Capability {
+	caps := make([]Capability, 0, len(l.Support))
+	for m, ok := range l.Support {
+		if !ok {
+			continue
+		}
+		if c, known := ParseCapability(m); known {
+			caps = append(caps, c)
+		}
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	return caps
+}
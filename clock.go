@@ -0,0 +1,52 @@
+package yeelight
+
+import "time"
+
+// Clock tells the time to lights and Managers, tests can
+// replace it to control timeouts and periodic refreshes
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the ticker of a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock sets the clock, the system clock by default
+func WithClock(c Clock) Option {
+	return func(cfg *config) {
+		if c != nil {
+			cfg.clock = c
+		}
+	}
+}
+
+// systemClock is the Clock of the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// clock returns light's clock, lights built by hand have none
+func (l *Light) clock() Clock {
+	if l.cfg.clock == nil {
+		return systemClock{}
+	}
+	return l.cfg.clock
+}
+
+// now returns the time of light's clock
+func (l *Light) now() time.Time {
+	return l.clock().Now()
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+func discover(a *app, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	if err := a.search(); err != nil {
+		return err
+	}
+	return a.print(a.m.Lights().Query())
+}
+
+func list(a *app, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	a.manager()
+	return a.print(a.reg.Query())
+}
+
+func state(a *app, args []string) error {
+	lights, err := a.targets(args)
+	if len(lights) == 0 {
+		return err
+	}
+	for _, l := range lights {
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+		if rerr := l.Refresh(ctx); rerr != nil && err == nil {
+			err = fmt.Errorf("%s: %w", a.label(l), rerr)
+		}
+		cancel()
+	}
+	if perr := a.print(lights); perr != nil {
+		return perr
+	}
+	return err
+}
+
+// lightInfo is a light as printed by -json
+type lightInfo struct {
+	yeelight.LightJSON
+	Alias string   `json:"alias,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// print writes a table of lights, or a JSON array with -json
+func (a *app) print(lights []*yeelight.Light) error {
+	if a.json {
+		infos := make([]lightInfo, 0, len(lights))
+		for _, l := range lights {
+			infos = append(infos, lightInfo{l.JSON(), a.reg.Alias(l.ID), a.reg.Tags(l.ID)})
+		}
+		return a.encode(infos)
+	}
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tALIAS\tNAME\tMODEL\tADDRESS\tPOWER\tBRIGHT\tTAGS")
+	for _, l := range lights {
+		j := l.JSON()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", j.ID, a.reg.Alias(j.ID), j.Name,
+			j.Model, j.Address, j.Power, j.Bright, strings.Join(a.reg.Tags(j.ID), ","))
+	}
+	return w.Flush()
+}
+
+// durationFlags returns the flags of commands taking a transition
+func durationFlags(name string) (*flag.FlagSet, *time.Duration) {
+	fset := flag.NewFlagSet(name, flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	d := fset.Duration("d", 0, "transition duration")
+	return fset, d
+}
+
+func power(state string) func(*app, []string) error {
+	return func(a *app, args []string) error {
+		fset, d := durationFlags(state)
+		if err := fset.Parse(args); err != nil {
+			return errUsage
+		}
+		effect, err := yeelight.Transition(*d).Params()
+		if err != nil {
+			return err
+		}
+		return a.call(fset.Args(), "set_power", append([]interface{}{state}, effect...)...)
+	}
+}
+
+func toggle(a *app, args []string) error {
+	return a.call(args, "toggle")
+}
+
+func bright(a *app, args []string) error {
+	return a.set("bright", args, "set_bright", strconv.Atoi)
+}
+
+func ct(a *app, args []string) error {
+	return a.set("ct", args, "set_ct_abx", strconv.Atoi)
+}
+
+func color(a *app, args []string) error {
+	return a.set("color", args, "set_rgb", parseRGB)
+}
+
+// set sends method with the value of args' first argument
+// parsed by parse and the transition of the -d flag
+func (a *app) set(name string, args []string, method string, parse func(string) (int, error)) error {
+	fset, d := durationFlags(name)
+	if err := fset.Parse(args); err != nil || fset.NArg() < 2 {
+		return errUsage
+	}
+	v, err := parse(fset.Arg(0))
+	if err != nil {
+		return fmt.Errorf("bad value %q", fset.Arg(0))
+	}
+	effect, err := yeelight.Transition(*d).Params()
+	if err != nil {
+		return err
+	}
+	return a.call(fset.Args()[1:], method, append([]interface{}{v}, effect...)...)
+}
+
+// parseRGB parses colors as rrggbb or #rrggbb
+func parseRGB(s string) (int, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return 0, errors.New("not rrggbb")
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	return int(v), err
+}
+
+var flowActions = map[string]yeelight.FlowAction{
+	"recover": yeelight.FlowRecover,
+	"stay":    yeelight.FlowStay,
+	"off":     yeelight.FlowOff,
+}
+
+func flow(a *app, args []string) error {
+	run := len(args) > 0 && args[0] == "run"
+	if run {
+		args = args[1:]
+	}
+	fset, d := durationFlags("flow")
+	count := fset.Int("count", 0, "transitions to run, 0 forever")
+	action := fset.String("action", "recover", "what to do when the flow ends")
+	if err := fset.Parse(args); err != nil || fset.NArg() < 2 {
+		return errUsage
+	}
+	act, ok := flowActions[*action]
+	if !ok {
+		return errUsage
+	}
+	if *d == 0 {
+		*d = 30 * time.Minute
+	}
+	var f *yeelight.Flow
+	switch expr := fset.Arg(0); {
+	case run:
+		var err error
+		if f, err = readFlow(expr); err != nil {
+			return err
+		}
+		if f.Count == 0 && f.Action == yeelight.FlowRecover {
+			f.Count, f.Action = *count, act
+		}
+	case expr == "sunrise":
+		f = yeelight.Sunrise(*d)
+	case expr == "sunset":
+		f = yeelight.Sunset(*d)
+	default:
+		transitions, err := yeelight.ParseFlowExpression(expr)
+		if err != nil {
+			return err
+		}
+		f = &yeelight.Flow{Count: *count, Action: act, Transitions: transitions}
+	}
+	expr, err := f.Expression()
+	if err != nil {
+		return err
+	}
+	return a.call(fset.Args()[1:], "start_cf", f.Count, int(f.Action), expr)
+}
+
+func scene(a *app, args []string) error {
+	if len(args) > 0 {
+		if sub, ok := sceneCommands[args[0]]; ok {
+			return sub(a, args[1:])
+		}
+	}
+	if a.cfg != nil && len(args) >= 2 {
+		if sc, ok := a.cfg.Scenes[args[0]]; ok {
+			return a.call(args[1:], "set_scene", append([]interface{}{sc.Class}, sc.Values...)...)
+		}
+	}
+	if len(args) < 3 {
+		return errUsage
+	}
+	params := []interface{}{args[0]}
+	for _, v := range strings.Split(args[1], ",") {
+		if n, err := strconv.Atoi(v); err == nil {
+			params = append(params, n)
+		} else {
+			params = append(params, v)
+		}
+	}
+	return a.call(args[2:], "set_scene", params...)
+}
+
+func alias(a *app, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errUsage
+	}
+	a.manager()
+	lights, unknown := a.resolve(args[:1])
+	if len(unknown) > 0 || len(lights) != 1 {
+		return fmt.Errorf("%q is not exactly one known light, try discover", args[0])
+	}
+	var name string
+	if len(args) == 2 {
+		name = args[1]
+	}
+	if err := a.reg.SetAlias(lights[0].ID, name); err != nil {
+		return err
+	}
+	if a.json {
+		return a.encode(map[string]string{"id": lights[0].ID, "alias": name})
+	}
+	return nil
+}
+
+// listFlag is a flag of comma separated values, it can be repeated
+type listFlag []string
+
+func (f *listFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *listFlag) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*f = append(*f, s)
+		}
+	}
+	return nil
+}
+
+func monitor(a *app, args []string) error {
+	fset := flag.NewFlagSet("monitor", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	var ids, props listFlag
+	fset.Var(&ids, "id", "lights to watch, comma separated")
+	fset.Var(&props, "prop", "properties to print, comma separated")
+	if err := fset.Parse(args); err != nil {
+		return errUsage
+	}
+	m := a.manager()
+	refs := append(ids, fset.Args()...)
+	if len(refs) == 0 {
+		refs = []string{"all"}
+	}
+	lights, err := a.targets(refs)
+	if len(lights) == 0 {
+		return err
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+	}
+	watched := make(map[string]*yeelight.Light)
+	for _, l := range lights {
+		watched[l.ID] = l
+	}
+	var mu sync.Mutex
+	m.AddEventHandler(yeelight.EventHandlerFunc(func(e yeelight.Event) {
+		l := watched[e.DevID]
+		if l == nil {
+			return
+		}
+		switch {
+		case e.Kind == yeelight.EventNotification && e.Notification != nil:
+			if len(props) > 0 {
+				n := *e.Notification
+				n.Params = make(map[string]interface{})
+				for _, p := range props {
+					if v, ok := e.Notification.Params[p]; ok {
+						n.Params[p] = v
+					}
+				}
+				if len(n.Params) == 0 {
+					return
+				}
+				e.Notification = &n
+			}
+		case e.Kind == yeelight.EventStatus && e.Status != nil:
+			if len(props) > 0 {
+				return
+			}
+		default:
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if a.json {
+			a.encode(e)
+			return
+		}
+		at := e.At.Format("15:04:05.000")
+		if e.Notification != nil {
+			for _, k := range sortedNames(e.Notification.Params) {
+				fmt.Fprintf(a.out, "%s %s %s=%v\n", at, a.label(l), k, e.Notification.Params[k])
+			}
+		} else {
+			fmt.Fprintf(a.out, "%s %s status=%s\n", at, a.label(l), e.Status.To)
+		}
+	}))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	<-ctx.Done()
+	return nil
+}
+
+// callResult is the result of a command on a light as printed by -json
+type callResult struct {
+	ID    string `json:"id,omitempty"`
+	Light string `json:"light"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// call sends method to the lights of refs reporting each result
+func (a *app) call(refs []string, method string, params ...interface{}) error {
+	lights, err := a.targets(refs)
+	if len(refs) == 0 {
+		return err
+	}
+	return a.each(lights, err, func(ctx context.Context, l *yeelight.Light) error {
+		_, err := l.Call(ctx, method, params...)
+		return err
+	})
+}
+
+// each runs do on lights concurrently reporting each result,
+// err is the error of finding the lights
+func (a *app) each(lights []*yeelight.Light, err error, do func(context.Context, *yeelight.Light) error) error {
+	var missing *unreachableError
+	if err != nil && !errors.As(err, &missing) {
+		return err
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failed := 0
+	results := make([]callResult, len(lights))
+	for i, l := range lights {
+		wg.Add(1)
+		go func(i int, l *yeelight.Light) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+			defer cancel()
+			cerr := do(ctx, l)
+			results[i] = callResult{ID: l.ID, Light: a.label(l), OK: cerr == nil}
+			if cerr != nil {
+				results[i].Error = cerr.Error()
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+			if !a.json {
+				a.ui.Status(a.label(l), cerr)
+			}
+		}(i, l)
+	}
+	wg.Wait()
+	if missing != nil {
+		for _, ref := range missing.refs {
+			results = append(results, callResult{Light: ref, Error: errUnreachable.Error()})
+			if !a.json {
+				a.ui.Status(ref, errUnreachable)
+			}
+		}
+		for _, l := range missing.lights {
+			results = append(results, callResult{ID: l.ID, Light: a.label(l), Error: errUnreachable.Error()})
+			if !a.json {
+				a.ui.Status(a.label(l), errUnreachable)
+			}
+		}
+		failed += len(missing.refs) + len(missing.lights)
+	}
+	if a.json {
+		if jerr := a.encode(results); jerr != nil {
+			return jerr
+		}
+	}
+	if failed > 0 {
+		return &lightsError{failed: failed, total: len(results)}
+	}
+	return nil
+}
+
+// lightsError reports the lights failing a command
+type lightsError struct {
+	failed, total int
+}
+
+func (e *lightsError) Error() string {
+	return fmt.Sprintf("%d of %d lights failed", e.failed, e.total)
+}
+
+// encode prints v as a line of JSON
+func (a *app) encode(v interface{}) error {
+	return json.NewEncoder(a.out).Encode(v)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/pulento/yeelight"
+)
+
+// Completion scripts, they ask "yeelightctl __complete <command>"
+// for the words to complete
+var completions = map[string]string{
+	"bash": `_yeelightctl() {
+	local cur=${COMP_WORDS[COMP_CWORD]} cmd=commands i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case ${COMP_WORDS[i]} in
+		-*) ;;
+		*) cmd=${COMP_WORDS[i]}; break ;;
+		esac
+	done
+	local IFS=$'\n'
+	COMPREPLY=($(compgen -W "$(yeelightctl __complete "$cmd" 2>/dev/null)" -- "$cur"))
+}
+complete -F _yeelightctl yeelightctl
+`,
+	"zsh": `#compdef yeelightctl
+_yeelightctl() {
+	local cmd=commands w
+	for w in ${words[2,CURRENT-1]}; do
+		if [[ $w != -* ]]; then
+			cmd=$w
+			break
+		fi
+	done
+	local -a candidates
+	candidates=(${(f)"$(yeelightctl __complete $cmd 2>/dev/null)"})
+	compadd -a candidates
+}
+compdef _yeelightctl yeelightctl
+`,
+	"fish": `function __yeelightctl_complete
+	set -l cmd commands
+	for w in (commandline -opc)[2..-1]
+		if not string match -q -- '-*' $w
+			set cmd $w
+			break
+		end
+	end
+	yeelightctl __complete $cmd 2>/dev/null
+end
+complete -c yeelightctl -f -a '(__yeelightctl_complete)'
+`,
+}
+
+// completion prints the completion script of a shell
+func completion(a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	script, ok := completions[args[0]]
+	if !ok {
+		return errUsage
+	}
+	_, err := fmt.Fprint(a.out, script)
+	return err
+}
+
+// complete prints the words completing the arguments of a command,
+// lights come from the registry without looking for them
+func complete(a *app, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	var words []string
+	switch args[0] {
+	case "commands":
+		for _, c := range commands {
+			if !strings.HasPrefix(c.name, "__") {
+				words = append(words, c.name)
+			}
+		}
+	case "completion":
+		words = sortedNames(completions)
+	case "config":
+		words = []string{"check"}
+	case "discover", "list":
+	case "scene":
+		words = append(words, "save", "apply", "list", "delete")
+		if scenes, err := a.loadScenes(); err == nil {
+			words = append(words, sortedNames(scenes)...)
+		}
+		if a.cfg != nil {
+			words = append(words, sortedNames(a.cfg.Scenes)...)
+		}
+		words = append(words, a.lightWords()...)
+	case "flow":
+		words = append([]string{"run", "sunrise", "sunset"}, a.lightWords()...)
+	default:
+		words = a.lightWords()
+	}
+	for _, w := range words {
+		fmt.Fprintln(a.out, w)
+	}
+	return nil
+}
+
+// lightWords returns the aliases, IDs and groups naming lights
+func (a *app) lightWords() []string {
+	reg := yeelight.NewLights()
+	if _, err := reg.Load(a.registry); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+	}
+	seen := map[string]bool{"all": true}
+	for _, l := range reg.Query() {
+		for _, w := range []string{reg.Alias(l.ID), l.ID, l.Name} {
+			// Names with spaces would be split by shells
+			if w != "" && !strings.ContainsAny(w, " \t") {
+				seen[w] = true
+			}
+		}
+	}
+	if a.cfg != nil {
+		for name := range a.cfg.Groups {
+			seen[name] = true
+		}
+	}
+	return sortedNames(seen)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	stdlog "log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pulento/yeelight"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the configuration file of yeelightctl, in YAML:
+//
+//	interface: 192.168.1.10
+//	timeout: 3s
+//	log_level: warn
+//	lights:
+//	  - {id: "0x0000000012345678", alias: desk, tags: [office]}
+//	  - {address: 192.168.1.30, alias: porch}
+//	groups:
+//	  office: [desk, lamp]
+//	scenes:
+//	  reading: {class: ct, values: [4000, 80]}
+//	http: ":8080"
+//	every: 5m
+//	alive: 30s
+//	mqtt:
+//	  broker: tcp://192.168.1.2:1883
+//	  prefix: yeelight
+//
+// or in TOML or JSON with the same keys for files ending in .toml
+// or .json. Flags given on the command line take precedence
+type config struct {
+	// Interface is the local address to search lights from
+	Interface string `json:"interface,omitempty" yaml:"interface" toml:"interface"`
+	Registry  string `json:"registry,omitempty" yaml:"registry" toml:"registry"`
+	// Timeout is how long to wait for each light, like "5s"
+	Timeout string `json:"timeout,omitempty" yaml:"timeout" toml:"timeout"`
+	// LogLevel is debug, info, warn or error, nothing is logged if empty
+	LogLevel string `json:"log_level,omitempty" yaml:"log_level" toml:"log_level"`
+	// Lights with an address are reached without discovery
+	Lights []lightConfig `json:"lights,omitempty" yaml:"lights" toml:"lights"`
+	// Groups are names for several lights, usable wherever a light is
+	Groups map[string][]string    `json:"groups,omitempty" yaml:"groups" toml:"groups"`
+	Scenes map[string]sceneConfig `json:"scenes,omitempty" yaml:"scenes" toml:"scenes"`
+	// HTTP, Every and Alive are the defaults of daemon's flags
+	HTTP  string `json:"http,omitempty" yaml:"http" toml:"http"`
+	Every string `json:"every,omitempty" yaml:"every" toml:"every"`
+	Alive string `json:"alive,omitempty" yaml:"alive" toml:"alive"`
+	// MQTT is the broker of daemon's MQTT bridge
+	MQTT *mqttConfig `json:"mqtt,omitempty" yaml:"mqtt" toml:"mqtt"`
+}
+
+// mqttConfig is where the MQTT bridge connects, see package mqttbridge
+type mqttConfig struct {
+	// Broker is a URL like tcp://host:1883 or ssl://host:8883
+	Broker   string `json:"broker,omitempty" yaml:"broker" toml:"broker"`
+	Prefix   string `json:"prefix,omitempty" yaml:"prefix" toml:"prefix"`
+	ClientID string `json:"client_id,omitempty" yaml:"client_id" toml:"client_id"`
+	Username string `json:"username,omitempty" yaml:"username" toml:"username"`
+	Password string `json:"password,omitempty" yaml:"password" toml:"password"`
+}
+
+// Schemes of the MQTT brokers paho connects to
+var mqttSchemes = map[string]bool{"tcp": true, "mqtt": true, "ssl": true, "tls": true, "mqtts": true, "ws": true, "wss": true}
+
+// lightConfig names a light by ID or address
+type lightConfig struct {
+	ID      string   `json:"id,omitempty" yaml:"id" toml:"id"`
+	Address string   `json:"address,omitempty" yaml:"address" toml:"address"`
+	Alias   string   `json:"alias,omitempty" yaml:"alias" toml:"alias"`
+	Tags    []string `json:"tags,omitempty" yaml:"tags" toml:"tags"`
+}
+
+// sceneConfig is a set_scene class and its values
+type sceneConfig struct {
+	Class  string        `json:"class" yaml:"class" toml:"class"`
+	Values []interface{} `json:"values" yaml:"values" toml:"values"`
+}
+
+// Values taken by set_scene per class, the range allows optional ones
+var sceneValues = map[string][2]int{
+	"color":          {1, 2},
+	"hsv":            {2, 3},
+	"ct":             {1, 2},
+	"cf":             {3, 3},
+	"auto_delay_off": {2, 2},
+}
+
+var logLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+// defaultConfig returns the config path in the user's config dir
+func defaultConfig() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "yeelightctl.yaml"
+	}
+	return filepath.Join(dir, "yeelight", "config.yaml")
+}
+
+// loadConfig reads and checks the config at path
+func loadConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(config)
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		err = yaml.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// check returns all the problems of c joined
+func (c *config) check() error {
+	var errs []error
+	bad := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	durations := map[string]string{"timeout": c.Timeout, "every": c.Every, "alive": c.Alive}
+	for _, name := range []string{"timeout", "every", "alive"} {
+		if v := durations[name]; v != "" {
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				bad("%s: bad duration %q", name, v)
+			}
+		}
+	}
+	if _, ok := logLevels[c.LogLevel]; !ok && c.LogLevel != "" {
+		bad("log_level: %q is not debug, info, warn or error", c.LogLevel)
+	}
+	if c.HTTP != "" {
+		if _, _, err := net.SplitHostPort(c.HTTP); err != nil {
+			bad("http: %s", err)
+		}
+	}
+	if m := c.MQTT; m != nil {
+		if u, err := url.Parse(m.Broker); err != nil || !mqttSchemes[u.Scheme] || u.Host == "" {
+			bad("mqtt.broker: %q is not a broker URL like tcp://host:1883", m.Broker)
+		}
+	}
+	aliases := make(map[string]bool)
+	for i, l := range c.Lights {
+		if l.ID == "" && l.Address == "" {
+			bad("lights[%d]: needs an id or an address", i)
+		}
+		if l.Alias != "" {
+			if aliases[l.Alias] {
+				bad("lights[%d]: alias %q used twice", i, l.Alias)
+			}
+			aliases[l.Alias] = true
+		}
+	}
+	for name, refs := range c.Groups {
+		if len(refs) == 0 {
+			bad("groups.%s: no lights", name)
+		}
+		if aliases[name] {
+			bad("groups.%s: also an alias", name)
+		}
+	}
+	for name, s := range c.Scenes {
+		n, ok := sceneValues[s.Class]
+		switch {
+		case !ok:
+			bad("scenes.%s: unknown class %q", name, s.Class)
+		case len(s.Values) < n[0] || len(s.Values) > n[1]:
+			bad("scenes.%s: %d values for class %s", name, len(s.Values), s.Class)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply sets the values of c as defaults of the flags not set
+func (a *app) apply(c *config, set map[string]bool) {
+	a.cfg = c
+	if c.Interface != "" && !set["l"] {
+		a.local = c.Interface
+	}
+	if c.Registry != "" && !set["registry"] {
+		a.registry = c.Registry
+	}
+	if c.Timeout != "" && !set["timeout"] {
+		a.timeout, _ = time.ParseDuration(c.Timeout)
+	}
+	if c.LogLevel != "" {
+		yeelight.SetLogger(&logger{min: logLevels[c.LogLevel], out: stdlog.New(os.Stderr, "", stdlog.LstdFlags)})
+	}
+}
+
+// readConfig loads the config of the -config flag, the default
+// one is optional
+func (a *app) readConfig(path string, set map[string]bool) error {
+	c, err := loadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) && !set["config"] {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	a.apply(c, set)
+	return nil
+}
+
+// configure adds the aliases and tags of the config to the registry
+func (a *app) configure() {
+	if a.cfg == nil {
+		return
+	}
+	for _, lc := range a.cfg.Lights {
+		for _, l := range a.reg.Query(func(l *yeelight.Light) bool { return lc.matches(l) }) {
+			if lc.Alias != "" {
+				if err := a.reg.SetAlias(l.ID, lc.Alias); err != nil {
+					fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+				}
+			}
+			a.reg.Tag(l.ID, lc.Tags...)
+		}
+	}
+}
+
+// matches returns true if l is the configured light
+func (lc lightConfig) matches(l *yeelight.Light) bool {
+	if lc.ID != "" {
+		return l.ID == lc.ID
+	}
+	host, _, err := net.SplitHostPort(l.Address)
+	return l.Address == lc.Address || (err == nil && host == lc.Address)
+}
+
+// staticLights returns the addresses of the configured lights
+func (a *app) staticLights() yeelight.StaticDiscoverer {
+	var addrs yeelight.StaticDiscoverer
+	if a.cfg != nil {
+		for _, lc := range a.cfg.Lights {
+			if lc.Address != "" {
+				addrs = append(addrs, lc.Address)
+			}
+		}
+	}
+	return addrs
+}
+
+// configCheck validates a config file
+func configCheck(a *app, args []string) error {
+	if len(args) == 0 || args[0] != "check" || len(args) > 2 {
+		return errUsage
+	}
+	path := a.config
+	if len(args) == 2 {
+		path = args[1]
+	}
+	if _, err := loadConfig(path); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.out, path, "is valid")
+	return nil
+}
+
+// logger is a yeelight.Logger writing the messages of at least level min
+type logger struct {
+	min    int
+	out    *stdlog.Logger
+	fields string
+}
+
+func (l *logger) WithField(key string, value interface{}) yeelight.Logger {
+	return &logger{min: l.min, out: l.out, fields: fmt.Sprintf("%s %s=%v", l.fields, key, value)}
+}
+
+func (l *logger) WithFields(fields yeelight.Fields) yeelight.Logger {
+	var lg yeelight.Logger = l
+	for _, k := range sortedNames(fields) {
+		lg = lg.WithField(k, fields[k])
+	}
+	return lg
+}
+
+func (l *logger) Debugf(format string, args ...interface{}) { l.printf(0, format, args) }
+func (l *logger) Infof(format string, args ...interface{})  { l.printf(1, format, args) }
+func (l *logger) Warnf(format string, args ...interface{})  { l.printf(2, format, args) }
+func (l *logger) Errorf(format string, args ...interface{}) { l.printf(3, format, args) }
+
+func (l *logger) printf(level int, format string, args []interface{}) {
+	if level < l.min {
+		return
+	}
+	names := [...]string{"DEBUG", "INFO", "WARN", "ERROR"}
+	l.out.Printf("%-5s %s%s", names[level], fmt.Sprintf(format, args...), l.fields)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The same config in each format
+var configs = map[string]string{
+	"config.yaml": `
+timeout: 3s
+lights:
+  - {id: "0x01", alias: desk, tags: [office]}
+  - {address: 192.168.1.30, alias: porch}
+groups:
+  office: [desk]
+scenes:
+  reading: {class: ct, values: [4000, 80]}
+mqtt:
+  broker: tcp://192.168.1.2:1883
+  client_id: lights
+`,
+	"config.toml": `
+timeout = "3s"
+
+[[lights]]
+id = "0x01"
+alias = "desk"
+tags = ["office"]
+
+[[lights]]
+address = "192.168.1.30"
+alias = "porch"
+
+[groups]
+office = ["desk"]
+
+[scenes.reading]
+class = "ct"
+values = [4000, 80]
+
+[mqtt]
+broker = "tcp://192.168.1.2:1883"
+client_id = "lights"
+`,
+	"config.json": `{
+"timeout": "3s",
+"lights": [{"id": "0x01", "alias": "desk", "tags": ["office"]}, {"address": "192.168.1.30", "alias": "porch"}],
+"groups": {"office": ["desk"]},
+"scenes": {"reading": {"class": "ct", "values": [4000, 80]}},
+"mqtt": {"broker": "tcp://192.168.1.2:1883", "client_id": "lights"}
+}`,
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	dir := t.TempDir()
+	loaded := make(map[string]*config)
+	for name, data := range configs {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := loadConfig(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// Numbers decode to different types per format
+		for k, s := range c.Scenes {
+			s.Values = nil
+			c.Scenes[k] = s
+		}
+		loaded[name] = c
+	}
+	want := loaded["config.yaml"]
+	if want.MQTT == nil || want.MQTT.ClientID != "lights" || len(want.Lights) != 2 || want.Lights[1].Address != "192.168.1.30" {
+		t.Fatalf("config.yaml decoded as %+v", want)
+	}
+	for _, name := range []string{"config.toml", "config.json"} {
+		if !reflect.DeepEqual(loaded[name], want) {
+			t.Errorf("%s = %+v, want %+v", name, loaded[name], want)
+		}
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.toml")
+	data := `
+timeout = "soon"
+log_level = "loud"
+
+[mqtt]
+broker = "localhost"
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := loadConfig(path)
+	if err == nil {
+		t.Fatal("invalid config accepted")
+	}
+	for _, want := range []string{"timeout", "log_level", "mqtt.broker"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
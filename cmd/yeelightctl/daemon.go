@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/httpapi"
+)
+
+// daemon keeps the lights connected, searching new ones every period
+// and saving the registry after each search, until interrupted. It
+// serves the REST API and bridges the lights to an MQTT broker
+func daemon(a *app, args []string) error {
+	fset := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	defAddr, defEvery, defAlive, defBroker := ":8080", 5*time.Minute, 30*time.Second, ""
+	if c := a.cfg; c != nil {
+		if c.MQTT != nil {
+			defBroker = c.MQTT.Broker
+		}
+		if c.HTTP != "" {
+			defAddr = c.HTTP
+		}
+		if c.Every != "" {
+			defEvery, _ = time.ParseDuration(c.Every)
+		}
+		if c.Alive != "" {
+			defAlive, _ = time.ParseDuration(c.Alive)
+		}
+	}
+	addr := fset.String("http", defAddr, "REST API address, empty disables it")
+	every := fset.Duration("every", defEvery, "discovery period")
+	alive := fset.Duration("alive", defAlive, "period to check silent lights")
+	broker := fset.String("mqtt", defBroker, "MQTT broker URL, empty disables the bridge")
+	if err := fset.Parse(args); err != nil || fset.NArg() > 0 || *every <= 0 {
+		return errUsage
+	}
+
+	logger := log.New(os.Stderr, "yeelightctl: ", log.LstdFlags)
+	a.m = a.newManager(
+		yeelight.WithLivenessCheck(*alive, a.timeout),
+		yeelight.WithEventHandler(yeelight.EventHandlerFunc(func(e yeelight.Event) {
+			switch e.Kind {
+			case yeelight.EventDiscovery:
+				logger.Printf("found %s at %s", e.DevID, e.Address)
+			case yeelight.EventAddressChanged:
+				logger.Printf("%s moved from %s to %s", e.DevID, e.OldAddress, e.Address)
+			case yeelight.EventStatus:
+				logger.Printf("%s is %s", e.DevID, e.Status.To)
+			}
+		})))
+	// The API and the registry share the Manager's lights
+	a.reg = a.m.Lights()
+	if err := a.m.Restore(a.registry); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.Println("restoring registry:", err)
+	}
+	a.configure()
+	if err := a.m.Monitor(); err != nil {
+		logger.Println("not listening announcements:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *addr != "" {
+		ln, err := net.Listen("tcp", *addr)
+		if err != nil {
+			return err
+		}
+		api := httpapi.New(a.reg)
+		api.Timeout = a.timeout
+		srv := &http.Server{Handler: api}
+		go srv.Serve(ln)
+		defer func() {
+			sctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+			defer cancel()
+			srv.Shutdown(sctx)
+		}()
+		logger.Println("serving REST API on", ln.Addr())
+	}
+	if *broker != "" {
+		disconnect, err := a.startMQTT(*broker, logger)
+		if err != nil {
+			return err
+		}
+		defer disconnect()
+		logger.Println("bridging lights to MQTT broker", *broker)
+	}
+
+	tick := time.NewTicker(*every)
+	defer tick.Stop()
+	for {
+		if err := a.m.Search(a.wait); err != nil {
+			logger.Println("search:", err)
+		}
+		a.configure()
+		if err := a.save(); err != nil {
+			logger.Println("cannot save registry:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+// identify flashes lights so they can be told apart, the lights
+// return to their previous state afterwards
+func identify(a *app, args []string) error {
+	fset := flag.NewFlagSet("identify", flag.ContinueOnError)
+	fset.SetOutput(io.Discard)
+	times := fset.Int("n", 3, "number of flashes")
+	if err := fset.Parse(args); err != nil || fset.NArg() == 0 || *times < 1 {
+		return errUsage
+	}
+	lights, err := a.targets(fset.Args())
+	return a.each(lights, err, func(ctx context.Context, l *yeelight.Light) error {
+		if err := l.Refresh(ctx); err != nil {
+			return err
+		}
+		f := identifyFlow(l.ModelSpec(), *times)
+		if !l.PowerState().IsOn() {
+			// Flows need the light on, it is turned off again at the end
+			f.Action = yeelight.FlowOff
+			if _, err := l.Call(ctx, "set_power", "on", "sudden", 0); err != nil {
+				return err
+			}
+		}
+		expr, err := f.Expression()
+		if err != nil {
+			return err
+		}
+		_, err = l.Call(ctx, "start_cf", f.Count, int(f.Action), expr)
+		return err
+	})
+}
+
+// identifyFlow returns a flow flashing a light of model m times
+func identifyFlow(m *yeelight.Model, times int) *yeelight.Flow {
+	if m.Color {
+		return yeelight.Flash(0x00ff00, times, yeelight.FlowRecover)
+	}
+	return &yeelight.Flow{
+		Count:  times * 2,
+		Action: yeelight.FlowRecover,
+		Transitions: []yeelight.FlowTransition{
+			{Duration: 250 * time.Millisecond, Mode: yeelight.FlowCT, Value: m.MaxCT, Bright: 100},
+			{Duration: 250 * time.Millisecond, Mode: yeelight.FlowCT, Value: m.MaxCT, Bright: 1},
+		},
+	}
+}
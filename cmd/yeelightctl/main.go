@@ -0,0 +1,373 @@
+// Command yeelightctl controls Yeelight lights on the LAN.
+//
+//	yeelightctl [flags] <command> [args]
+//
+// Lights are targeted by ID, alias, name or IP, "all" targets every
+// light found. Lights found are kept in a registry so commands reach
+// known lights without waiting for discovery.
+//
+// With -json lists and results are printed as JSON and monitor prints
+// one JSON event per line.
+//
+// Commands acting on several lights report each one. The exit code is
+// 0 on success, 1 on errors, 2 on bad usage and 3 when only some of
+// the lights failed.
+//
+// Settings, static lights, aliases, groups and scenes can be given in
+// a YAML, TOML or JSON config file, see config, checked with "config check".
+//
+// The daemon command keeps every light connected, searching new ones
+// periodically, and serves them with the REST API of package httpapi
+// and, given a broker, over MQTT with package mqttbridge.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/internal/termui"
+)
+
+// command is a subcommand of yeelightctl
+type command struct {
+	name string
+	args string
+	help string
+	run  func(a *app, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"discover", "", "search lights and list them", discover},
+		{"list", "", "list the lights in the registry", list},
+		{"state", "<light>...", "show the current state of lights", state},
+		{"on", "[-d duration] <light>...", "turn lights on", power("on")},
+		{"off", "[-d duration] <light>...", "turn lights off", power("off")},
+		{"toggle", "<light>...", "toggle lights", toggle},
+		{"bright", "[-d duration] <1-100> <light>...", "set brightness", bright},
+		{"ct", "[-d duration] <kelvin> <light>...", "set color temperature", ct},
+		{"color", "[-d duration] <rrggbb> <light>...", "set RGB color", color},
+		{"flow", "[run] [-count n] [-action recover|stay|off] [-d duration] <expression|sunrise|sunset|file> <light>...", "start a color flow, run reads it from a file", flow},
+		{"scene", "<class> <values> <light>... | <name> <light>... | save|apply|list|delete", "set a scene, like: scene ct 2700,40 desk", scene},
+		{"identify", "[-n times] <light>...", "flash lights to find them, they go back to their state", identify},
+		{"alias", "<light> [alias]", "set or remove the alias of a light", alias},
+		{"monitor", "[-id light,...] [-prop power,bright,...] [light]...", "print lights' changes until interrupted", monitor},
+		{"completion", "bash|zsh|fish", "print the shell completion script", completion},
+		{"__complete", "<command>", "print the completions of a command", complete},
+		{"config", "check [file]", "validate a config file", configCheck},
+		{"daemon", "[-http addr] [-mqtt broker] [-every duration] [-alive duration]", "keep lights connected serving the REST API and MQTT", daemon},
+	}
+}
+
+// errUsage reports bad arguments, the usage is printed
+var errUsage = errors.New("bad usage")
+
+// errUnreachable is reported for lights not found or not connected
+var errUnreachable = errors.New("not reachable")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout))
+}
+
+// app is the state shared by commands
+type app struct {
+	wait     int
+	local    string
+	registry string
+	config   string
+	scenes   string
+	cfg      *config
+	timeout  time.Duration
+	json     bool
+	out      io.Writer
+	ui       *termui.UI
+	m        *yeelight.Manager
+	reg      *yeelight.Registry
+}
+
+func run(args []string, out io.Writer) int {
+	a := &app{out: out, ui: termui.New(out)}
+	fset := flag.NewFlagSet("yeelightctl", flag.ContinueOnError)
+	fset.IntVar(&a.wait, "w", 1, "SSDP search wait in seconds")
+	fset.StringVar(&a.local, "l", "", "local address to search from")
+	fset.StringVar(&a.config, "config", defaultConfig(), "config file")
+	fset.StringVar(&a.registry, "registry", defaultRegistry(), "file keeping the lights found")
+	fset.StringVar(&a.scenes, "scenes", filepath.Join(filepath.Dir(defaultRegistry()), "scenes.json"), "file keeping the saved scenes")
+	fset.DurationVar(&a.timeout, "timeout", 5*time.Second, "how long to wait for each light")
+	fset.BoolVar(&a.json, "json", false, "print JSON")
+	fset.Usage = func() { usage(fset) }
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return 2
+	}
+	set := make(map[string]bool)
+	fset.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if fset.Arg(0) != "config" {
+		if err := a.readConfig(a.config, set); err != nil {
+			fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+			return 1
+		}
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == fset.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "yeelightctl: unknown command %q\n", fset.Arg(0))
+		fset.Usage()
+		return 2
+	}
+	err := cmd.run(a, fset.Args()[1:])
+	a.close()
+	var lerr *lightsError
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "usage: yeelightctl %s %s\n", cmd.name, cmd.args)
+		return 2
+	case errors.As(err, &lerr) && lerr.failed < lerr.total:
+		fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+		return 3
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "yeelightctl:", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(fset *flag.FlagSet) {
+	w := fset.Output()
+	fmt.Fprintln(w, "usage: yeelightctl [flags] <command> [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, c := range commands {
+		if strings.HasPrefix(c.name, "__") {
+			continue
+		}
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.help)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	fset.PrintDefaults()
+}
+
+// defaultRegistry returns the registry path in the user's config dir
+func defaultRegistry() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "yeelight.json"
+	}
+	return filepath.Join(dir, "yeelight", "lights.json")
+}
+
+// manager returns the Manager and the registry, loaded on first use
+func (a *app) manager() *yeelight.Manager {
+	if a.m != nil {
+		return a.m
+	}
+	a.m = a.newManager()
+	a.reg = yeelight.NewLights()
+	if _, err := a.reg.Load(a.registry); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "yeelightctl: ignoring registry:", err)
+	}
+	a.configure()
+	return a.m
+}
+
+// newManager returns a Manager with the timeouts of the flags,
+// finding the configured static lights along with SSDP ones
+func (a *app) newManager(opts ...yeelight.Option) *yeelight.Manager {
+	opts = append([]yeelight.Option{
+		yeelight.WithConnectTimeout(a.timeout),
+		yeelight.WithCommandTimeout(a.timeout),
+	}, opts...)
+	if static := a.staticLights(); len(static) > 0 {
+		opts = append(opts, yeelight.WithDiscoverer(yeelight.MultiDiscoverer(
+			yeelight.SSDPDiscoverer{LocalAddr: a.local}, static)))
+	}
+	m := yeelight.NewManager(a.local, opts...)
+	// Commands wait for their own results
+	go func() {
+		for range m.Events() {
+		}
+	}()
+	return m
+}
+
+// search discovers lights adding them to the registry
+func (a *app) search() error {
+	m := a.manager()
+	if err := m.Search(a.wait); err != nil {
+		return err
+	}
+	for l := range m.All() {
+		if known := a.reg.Get(l.ID); known != nil && known != l {
+			// The found light has the fresh address and state
+			if c := known.Calibration(); c != nil {
+				l.SetCalibration(c)
+			}
+		}
+		a.reg.Put(l)
+	}
+	a.configure()
+	return nil
+}
+
+// targets returns the connected lights of refs, known lights are
+// reached right away and discovery only runs for the others
+func (a *app) targets(refs []string) ([]*yeelight.Light, error) {
+	if len(refs) == 0 {
+		return nil, errUsage
+	}
+	m := a.manager()
+	lights, unknown := a.resolve(refs)
+	search := len(unknown) > 0
+	tried := make(map[*yeelight.Light]bool)
+	for _, ref := range refs {
+		search = search || ref == "all"
+	}
+	if !search {
+		for _, l := range lights {
+			tried[l] = true
+			if err := m.Add(l); err != nil {
+				// Probably moved to another address
+				search = true
+			}
+		}
+	}
+	if search {
+		if err := a.search(); err != nil {
+			return nil, err
+		}
+		lights, unknown = a.resolve(refs)
+	}
+	var connected, failed []*yeelight.Light
+	for _, l := range lights {
+		if m.Get(l.ID) != l {
+			if tried[l] || m.Add(l) != nil {
+				failed = append(failed, l)
+				continue
+			}
+		}
+		connected = append(connected, l)
+	}
+	switch {
+	case len(unknown) > 0 || len(failed) > 0:
+		return connected, &unreachableError{refs: unknown, lights: failed, label: a.label}
+	case len(connected) == 0:
+		return nil, errors.New("no lights found")
+	}
+	return connected, nil
+}
+
+// unreachableError reports the refs matching no light
+// and the lights not connected
+type unreachableError struct {
+	refs   []string
+	lights []*yeelight.Light
+	label  func(*yeelight.Light) string
+}
+
+func (e *unreachableError) Error() string {
+	names := append([]string(nil), e.refs...)
+	for _, l := range e.lights {
+		names = append(names, e.label(l))
+	}
+	return "lights not reachable: " + strings.Join(names, ", ")
+}
+
+// resolve returns the registry's lights matching refs
+// and the refs matching none
+func (a *app) resolve(refs []string) (lights []*yeelight.Light, unknown []string) {
+	seen := make(map[string]bool)
+	for _, ref := range a.expand(refs) {
+		var matched []*yeelight.Light
+		if l := a.reg.Lookup(ref); l != nil {
+			matched = []*yeelight.Light{l}
+		} else {
+			matched = a.reg.Query(func(l *yeelight.Light) bool { return matches(a.reg, l, ref) })
+		}
+		if len(matched) == 0 && ref != "all" {
+			unknown = append(unknown, ref)
+		}
+		for _, l := range matched {
+			if !seen[l.ID] {
+				seen[l.ID] = true
+				lights = append(lights, l)
+			}
+		}
+	}
+	return lights, unknown
+}
+
+// expand replaces the configured groups in refs by their lights
+func (a *app) expand(refs []string) []string {
+	if a.cfg == nil {
+		return refs
+	}
+	var out []string
+	for _, ref := range refs {
+		if group, ok := a.cfg.Groups[ref]; ok {
+			out = append(out, group...)
+		} else {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// matches returns true if ref is l's ID, alias, name or IP
+func matches(reg *yeelight.Registry, l *yeelight.Light, ref string) bool {
+	if ref == "all" || l.ID == ref || l.Name == ref || reg.Alias(l.ID) == ref {
+		return true
+	}
+	host, _, err := net.SplitHostPort(l.Address)
+	return err == nil && host == ref
+}
+
+// label returns how l is shown to users
+func (a *app) label(l *yeelight.Light) string {
+	name := a.reg.Alias(l.ID)
+	if name == "" {
+		name = l.Name
+	}
+	if name == "" {
+		return l.ID + " " + l.Address
+	}
+	return name + " (" + l.ID + " " + l.Address + ")"
+}
+
+// save writes the registry
+func (a *app) save() error {
+	if err := os.MkdirAll(filepath.Dir(a.registry), 0o755); err != nil {
+		return err
+	}
+	return a.reg.Save(a.registry)
+}
+
+// close saves the registry and closes the Manager
+func (a *app) close() {
+	if a.m == nil {
+		return
+	}
+	if err := a.save(); err != nil {
+		fmt.Fprintln(os.Stderr, "yeelightctl: cannot save registry:", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	a.m.Close(ctx)
+}
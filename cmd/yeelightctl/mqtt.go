@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pulento/yeelight/mqttbridge"
+)
+
+// startMQTT bridges the daemon's lights to broker with the settings
+// of the config, the returned func disconnects
+func (a *app) startMQTT(broker string, logger *log.Logger) (func(), error) {
+	var mc mqttConfig
+	if a.cfg != nil && a.cfg.MQTT != nil {
+		mc = *a.cfg.MQTT
+	}
+	if mc.ClientID == "" {
+		mc.ClientID = "yeelightctl"
+	}
+	bridge := mqttbridge.New(a.reg, mc.Prefix)
+	bridge.Timeout = a.timeout
+	a.m.AddEventHandler(bridge)
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(mc.ClientID).
+		SetUsername(mc.Username).
+		SetPassword(mc.Password).
+		SetConnectTimeout(a.timeout).
+		SetAutoReconnect(true).
+		SetWill(bridge.StatusTopic(), "offline", 1, true)
+	opts.SetOnConnectHandler(func(c mqtt.Client) {
+		if err := bridge.Start(c); err != nil {
+			logger.Println("mqtt:", err)
+		}
+	})
+	opts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		logger.Println("mqtt connection lost:", err)
+	})
+	c := mqtt.NewClient(opts)
+	t := c.Connect()
+	if !t.WaitTimeout(a.timeout) {
+		return nil, errors.New("mqtt: timeout connecting to " + broker)
+	}
+	if err := t.Error(); err != nil {
+		return nil, err
+	}
+	return func() {
+		c.Publish(bridge.StatusTopic(), 1, true, "offline").WaitTimeout(time.Second)
+		c.Disconnect(250)
+	}, nil
+}
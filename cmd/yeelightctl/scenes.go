@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pulento/yeelight"
+)
+
+// lightState is the state of a light kept by a saved scene
+type lightState struct {
+	ID        string              `json:"id"`
+	Power     yeelight.PowerState `json:"power"`
+	Bright    int                 `json:"bright"`
+	ColorMode int                 `json:"color_mode"`
+	CT        int                 `json:"ct,omitempty"`
+	RGB       int                 `json:"rgb,omitempty"`
+	Hue       int                 `json:"hue,omitempty"`
+	Sat       int                 `json:"sat,omitempty"`
+}
+
+// Color modes reported by lights
+const (
+	modeRGB = 1
+	modeCT  = 2
+	modeHSV = 3
+)
+
+var sceneCommands map[string]func(*app, []string) error
+
+func init() {
+	sceneCommands = map[string]func(*app, []string) error{
+		"save":   sceneSave,
+		"apply":  sceneApply,
+		"list":   sceneList,
+		"delete": sceneDelete,
+	}
+}
+
+// loadScenes returns the saved scenes by name
+func (a *app) loadScenes() (map[string][]lightState, error) {
+	scenes := make(map[string][]lightState)
+	data, err := os.ReadFile(a.scenes)
+	if errors.Is(err, fs.ErrNotExist) {
+		return scenes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &scenes); err != nil {
+		return nil, fmt.Errorf("%s: %w", a.scenes, err)
+	}
+	return scenes, nil
+}
+
+// saveScenes writes scenes replacing the saved ones
+func (a *app) saveScenes(scenes map[string][]lightState) error {
+	data, err := json.MarshalIndent(scenes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.scenes), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(a.scenes, data, 0o644)
+}
+
+// sceneSave captures the current state of lights as a scene
+func sceneSave(a *app, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	scenes, err := a.loadScenes()
+	if err != nil {
+		return err
+	}
+	lights, err := a.targets(args[1:])
+	var mu sync.Mutex
+	var states []lightState
+	err = a.each(lights, err, func(ctx context.Context, l *yeelight.Light) error {
+		if err := l.Refresh(ctx); err != nil {
+			return err
+		}
+		// Values sent back are calibrated and curved again when applied
+		j := l.JSON()
+		mu.Lock()
+		defer mu.Unlock()
+		states = append(states, lightState{ID: j.ID, Power: j.Power, Bright: l.PerceivedBright(),
+			ColorMode: j.ColorMode, CT: l.NominalCT(), RGB: l.NominalRGB(), Hue: j.Hue, Sat: j.Sat})
+		return nil
+	})
+	if len(states) == 0 {
+		return err
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	scenes[args[0]] = states
+	if serr := a.saveScenes(scenes); serr != nil {
+		return serr
+	}
+	return err
+}
+
+// sceneApply restores the lights of a saved scene, a configured
+// scene is set on the lights given
+func sceneApply(a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	if a.cfg != nil {
+		if _, ok := a.cfg.Scenes[args[0]]; ok {
+			return scene(a, args)
+		}
+	}
+	scenes, err := a.loadScenes()
+	if err != nil {
+		return err
+	}
+	states, ok := scenes[args[0]]
+	if !ok {
+		return fmt.Errorf("no scene %q", args[0])
+	}
+	byID := make(map[string]lightState)
+	refs := args[1:]
+	for _, s := range states {
+		byID[s.ID] = s
+		if len(args) == 1 {
+			refs = append(refs, s.ID)
+		}
+	}
+	lights, err := a.targets(refs)
+	return a.each(lights, err, func(ctx context.Context, l *yeelight.Light) error {
+		s, ok := byID[l.ID]
+		if !ok {
+			return fmt.Errorf("not in scene %q", args[0])
+		}
+		method, params := s.command()
+		_, err := l.Call(ctx, method, params...)
+		return err
+	})
+}
+
+// command returns the command setting the light to s
+func (s lightState) command() (string, []interface{}) {
+	if !s.Power.IsOn() {
+		return "set_power", []interface{}{"off", "sudden", 0}
+	}
+	switch s.ColorMode {
+	case modeRGB:
+		return "set_scene", []interface{}{"color", s.RGB, s.Bright}
+	case modeHSV:
+		return "set_scene", []interface{}{"hsv", s.Hue, s.Sat, s.Bright}
+	case modeCT:
+		return "set_scene", []interface{}{"ct", s.CT, s.Bright}
+	}
+	// White only lights have no color mode
+	return "set_bright", []interface{}{s.Bright, "sudden", 0}
+}
+
+// sceneList prints the saved and configured scenes
+func sceneList(a *app, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	scenes, err := a.loadScenes()
+	if err != nil {
+		return err
+	}
+	var configured map[string]sceneConfig
+	if a.cfg != nil {
+		configured = a.cfg.Scenes
+	}
+	if a.json {
+		return a.encode(map[string]interface{}{"saved": scenes, "configured": configured})
+	}
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SCENE\tKIND\tLIGHTS")
+	for _, name := range sortedNames(scenes) {
+		ids := make([]string, 0, len(scenes[name]))
+		for _, s := range scenes[name] {
+			ids = append(ids, s.ID)
+		}
+		fmt.Fprintf(w, "%s\tsaved\t%s\n", name, strings.Join(ids, ","))
+	}
+	for _, name := range sortedNames(configured) {
+		fmt.Fprintf(w, "%s\tconfigured\t%s %v\n", name, configured[name].Class, configured[name].Values)
+	}
+	return w.Flush()
+}
+
+// sceneDelete removes saved scenes
+func sceneDelete(a *app, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	scenes, err := a.loadScenes()
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if _, ok := scenes[name]; !ok {
+			return fmt.Errorf("no scene %q", name)
+		}
+		delete(scenes, name)
+	}
+	return a.saveScenes(scenes)
+}
+
+// readFlow reads a flow file, either a JSON yeelight.Flow or a flow
+// expression where lines may end in # comments
+func readFlow(path string) (*yeelight.Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := new(yeelight.Flow)
+	if text := strings.TrimSpace(string(data)); strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal(data, f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return f, nil
+	}
+	var expr []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.Trim(strings.TrimSpace(line), ","); line != "" {
+			expr = append(expr, line)
+		}
+	}
+	if f.Transitions, err = yeelight.ParseFlowExpression(strings.Join(expr, ",")); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
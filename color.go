@@ -0,0 +1,196 @@
+package yeelight
+
+import "math"
+
+// RGB packs red, green and blue components into the 0xRRGGBB
+// integer used by the lights
+func RGB(r, g, b uint8) uint32 {
+	return uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+}
+
+// SplitRGB returns the red, green and blue components of rgb
+func SplitRGB(rgb uint32) (r, g, b uint8) {
+	return uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb)
+}
+
+// RGBToHSV converts rgb to the hue (0-359) and saturation (0-100)
+// used by set_hsv, val is the value component (0-100)
+func RGBToHSV(rgb uint32) (hue uint16, sat uint8, val uint8) {
+	r, g, b := rgbFloats(rgb)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h := rgbHue(r, g, b, max, min)
+	s := 0.0
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return uint16(h) % 360, uint8(math.Round(s * 100)), uint8(math.Round(max * 100))
+}
+
+// HSVToRGB converts hue (0-359), saturation and value (0-100) to rgb
+func HSVToRGB(hue uint16, sat uint8, val uint8) uint32 {
+	s := math.Min(float64(sat), 100) / 100
+	v := math.Min(float64(val), 100) / 100
+	c := v * s
+	return hueToRGB(float64(hue%360), c, v-c)
+}
+
+// RGBToHSL converts rgb to hue (0-360), saturation and lightness (0-1)
+func RGBToHSL(rgb uint32) (h, s, l float64) {
+	r, g, b := rgbFloats(rgb)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	h = rgbHue(r, g, b, max, min)
+	l = (max + min) / 2
+	if max != min {
+		s = (max - min) / (1 - math.Abs(2*l-1))
+	}
+	return h, s, l
+}
+
+// HSLToRGB converts hue (0-360), saturation and lightness (0-1) to rgb
+func HSLToRGB(h, s, l float64) uint32 {
+	s = clamp01(s)
+	l = clamp01(l)
+	c := (1 - math.Abs(2*l-1)) * s
+	return hueToRGB(math.Mod(h, 360), c, l-c/2)
+}
+
+// RGBToXY converts rgb to CIE 1931 xy chromaticity coordinates
+// and brightness (0-1) as used by Hue/HomeKit style APIs
+func RGBToXY(rgb uint32) (x, y, bri float64) {
+	r, g, b := rgbFloats(rgb)
+	r, g, b = srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+	// sRGB D65 to XYZ
+	X := r*0.4124 + g*0.3576 + b*0.1805
+	Y := r*0.2126 + g*0.7152 + b*0.0722
+	Z := r*0.0193 + g*0.1192 + b*0.9505
+	sum := X + Y + Z
+	if sum == 0 {
+		// Black has no chromaticity, use D65 white point
+		return 0.3127, 0.3290, 0
+	}
+	return X / sum, Y / sum, Y
+}
+
+// XYToRGB converts CIE 1931 xy chromaticity coordinates and
+// brightness (0-1) to rgb. Colors out of the sRGB gamut are scaled
+func XYToRGB(x, y, bri float64) uint32 {
+	if y <= 0 {
+		return 0
+	}
+	Y := clamp01(bri)
+	X := Y / y * x
+	Z := Y / y * (1 - x - y)
+	// XYZ to linear sRGB D65
+	r := X*3.2406 - Y*1.5372 - Z*0.4986
+	g := -X*0.9689 + Y*1.8758 + Z*0.0415
+	b := X*0.0557 - Y*0.2040 + Z*1.0570
+	r, g, b = math.Max(r, 0), math.Max(g, 0), math.Max(b, 0)
+	if max := math.Max(r, math.Max(g, b)); max > 1 {
+		r, g, b = r/max, g/max, b/max
+	}
+	return floatsRGB(linearToSrgb(r), linearToSrgb(g), linearToSrgb(b))
+}
+
+// RGBToRGBW splits rgb into color and white components, moving the
+// common part of the three channels to white
+func RGBToRGBW(rgb uint32) (r, g, b, w uint8) {
+	r, g, b = SplitRGB(rgb)
+	w = r
+	if g < w {
+		w = g
+	}
+	if b < w {
+		w = b
+	}
+	return r - w, g - w, b - w, w
+}
+
+// RGBWToRGB merges color and white components back into rgb
+func RGBWToRGB(r, g, b, w uint8) uint32 {
+	add := func(c uint8) uint8 {
+		if int(c)+int(w) > 0xff {
+			return 0xff
+		}
+		return c + w
+	}
+	return RGB(add(r), add(g), add(b))
+}
+
+func rgbFloats(rgb uint32) (r, g, b float64) {
+	ri, gi, bi := SplitRGB(rgb)
+	return float64(ri) / 0xff, float64(gi) / 0xff, float64(bi) / 0xff
+}
+
+func floatsRGB(r, g, b float64) uint32 {
+	conv := func(c float64) uint8 {
+		return uint8(math.Round(clamp01(c) * 0xff))
+	}
+	return RGB(conv(r), conv(g), conv(b))
+}
+
+// rgbHue returns the hue in degrees of r, g, b with max and min
+// being the largest and smallest of the three
+func rgbHue(r, g, b, max, min float64) float64 {
+	d := max - min
+	if d == 0 {
+		return 0
+	}
+	var h float64
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// hueToRGB builds rgb from hue h, chroma c and lightness offset m
+func hueToRGB(h, c, m float64) uint32 {
+	if h < 0 {
+		h += 360
+	}
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return floatsRGB(r+m, g+m, b+m)
+}
+
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSrgb(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,107 @@
+package yeelight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ColorFrame is a color to show in a color stream
+type ColorFrame struct {
+	RGB uint32
+	// Bright is the brightness, 1-100, 0 keeps the current one
+	Bright int
+}
+
+// StreamColors drives light with the frames received until ctx is
+// done or frames is closed, sending at most fps updates per second
+// over music mode. Frames are coalesced, only the latest one is sent
+// on each tick, and frames is always drained so a slow light never
+// blocks the source. Music mode is started if needed and stopped on
+// return if it was started here
+func (l *Light) StreamColors(ctx context.Context, frames <-chan ColorFrame, fps int) error {
+	if fps <= 0 {
+		return fmt.Errorf("%w: fps %d", errInvalidParam, fps)
+	}
+	if l.musicConn() == nil {
+		if err := l.StartMusic(""); err != nil {
+			return err
+		}
+		defer l.StopMusic()
+	}
+
+	var (
+		mu     sync.Mutex
+		latest ColorFrame
+		fresh  bool
+	)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case f, ok := <-frames:
+				if !ok {
+					return
+				}
+				mu.Lock()
+				latest, fresh = f, true
+				mu.Unlock()
+			}
+		}
+	}()
+
+	tick := l.clock().NewTicker(time.Second / time.Duration(fps))
+	defer tick.Stop()
+	var last ColorFrame
+	first := true
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-closed:
+			// Show the last frame received before leaving
+			mu.Lock()
+			f, ok := latest, fresh
+			mu.Unlock()
+			if ok && (first || f != last) {
+				return l.sendColor(f, last, first)
+			}
+			return ctx.Err()
+		case <-tick.C():
+			mu.Lock()
+			f, ok := latest, fresh
+			fresh = false
+			mu.Unlock()
+			if !ok || (!first && f == last) {
+				continue
+			}
+			if err := l.sendColor(f, last, first); err != nil {
+				return err
+			}
+			last, first = f, false
+		}
+	}
+}
+
+// sendColor shows f, with a scene if supported, sending the
+// brightness only if it changed from prev
+func (l *Light) sendColor(f, prev ColorFrame, first bool) error {
+	if f.Bright > 0 && l.Support["set_scene"] {
+		_, err := l.SendCommand("set_scene", "color", f.RGB, f.Bright)
+		return err
+	}
+	if first || f.RGB != prev.RGB {
+		if _, err := l.SendCommand("set_rgb", f.RGB, "sudden", 0); err != nil {
+			return err
+		}
+	}
+	if f.Bright > 0 && (first || f.Bright != prev.Bright) {
+		_, err := l.SendCommand("set_bright", f.Bright, "sudden", 0)
+		return err
+	}
+	return nil
+}
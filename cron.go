@@ -0,0 +1,157 @@
+package yeelight
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronFields are the allowed values of each field of a cron expression
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Shorthands of common cron expressions
+var cronMacros = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// cron is a parsed cron expression, a set of allowed values per field
+type cron struct {
+	fields [5]map[int]bool
+	// Days match if either day field matches when both are restricted
+	anyDom, anyDow bool
+}
+
+// ParseCron parses a standard five fields cron expression, "minute
+// hour day-of-month month day-of-week" with lists, ranges and steps
+// like "*/15 8-18 * * 1-5", the @daily like macros or "@every 10m"
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := strings.CutPrefix(expr, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("%w: cron interval %q", errInvalidParam, d)
+		}
+		return Every(every), nil
+	}
+	if m, ok := cronMacros[expr]; ok {
+		expr = m
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: cron expression %q needs 5 fields", errInvalidParam, expr)
+	}
+	c := &cron{anyDom: parts[2] == "*", anyDow: parts[4] == "*"}
+	for i, p := range parts {
+		set, err := parseCronField(p, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cron %s %q", errInvalidParam, cronFields[i].name, p)
+		}
+		c.fields[i] = set
+	}
+	// Sunday is both 0 and 7
+	if c.fields[4][7] {
+		c.fields[4][0] = true
+	}
+	return c.next, nil
+}
+
+// parseCronField returns the values matched by field f
+func parseCronField(f string, min, max int) (map[int]bool, error) {
+	set := make(map[int]bool)
+	if max == 6 {
+		// Day of week accepts 7 as Sunday
+		max = 7
+	}
+	for _, item := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return nil, errInvalidParam
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return nil, errInvalidParam
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return nil, errInvalidParam
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return nil, errInvalidParam
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// Longest search for the next run, leap days every 4 years
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// next implements Schedule
+func (c *cron) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.Add(cronHorizon)
+	for t.Before(end) {
+		if !c.fields[3][int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.day(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.fields[1][t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.fields[0][t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// day returns true if t's day matches, like cron when both day
+// fields are restricted either of them matching is enough
+func (c *cron) day(t time.Time) bool {
+	dom := c.fields[2][t.Day()]
+	dow := c.fields[4][int(t.Weekday())]
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dow
+	case c.anyDow:
+		return dom
+	}
+	return dom || dow
+}
@@ -0,0 +1,170 @@
+package yeelight
+
+import (
+	"context"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// DeviceClass is the kind of a Yeelight product
+type DeviceClass int
+
+// Device classes
+const (
+	ClassUnknown DeviceClass = iota
+	ClassBulb
+	ClassStrip
+	ClassCeiling
+	ClassLamp
+)
+
+var deviceClassNames = map[DeviceClass]string{
+	ClassUnknown: "unknown",
+	ClassBulb:    "bulb",
+	ClassStrip:   "strip",
+	ClassCeiling: "ceiling",
+	ClassLamp:    "lamp",
+}
+
+// String returns the name of the device class
+func (c DeviceClass) String() string {
+	return deviceClassNames[c]
+}
+
+// Model name prefixes of each class, longer prefixes first
+var classPrefixes = []struct {
+	prefix string
+	class  DeviceClass
+}{
+	{"stripe", ClassStrip},
+	{"strip", ClassStrip},
+	{"ceil", ClassCeiling},
+	{"bslamp", ClassLamp},
+	{"desklamp", ClassLamp},
+	{"lamp", ClassLamp},
+	{"mono", ClassBulb},
+	{"ct_bulb", ClassBulb},
+	{"color", ClassBulb},
+}
+
+// ModelClass returns the class of products reporting model
+func ModelClass(model string) DeviceClass {
+	for _, p := range classPrefixes {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.class
+		}
+	}
+	return ClassUnknown
+}
+
+// DeviceInfo identifies a device
+type DeviceInfo struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Address string      `json:"address"`
+	Model   string      `json:"model"`
+	FW      int         `json:"fw"`
+	Class   DeviceClass `json:"class"`
+}
+
+// Device is a Yeelight product controlled with the LAN protocol,
+// *Light implements it for bulbs and every other class speaking
+// the same protocol. Class specific features, like the segments
+// of LED strips, are left to types implementing it too
+type Device interface {
+	// Info returns the identity of the device
+	Info() DeviceInfo
+	// Supports returns true if the device supports capability c
+	Supports(c Capability) bool
+	Connect(opts ...Option) error
+	Listen(notifCh chan<- *ResultNotification, opts ...Option) (*Listener, error)
+	SendCommand(method string, params ...interface{}) (int32, error)
+	Call(ctx context.Context, method string, params ...interface{}) (*Result, error)
+	Close() error
+}
+
+var _ Device = (*Light)(nil)
+
+// Info implements Device
+func (l *Light) Info() DeviceInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return DeviceInfo{
+		ID:      l.ID,
+		Name:    l.Name,
+		Address: l.Address,
+		Model:   l.Model,
+		FW:      l.FW,
+		Class:   ModelClass(l.Model),
+	}
+}
+
+// Devices returns the devices of the collection sorted by ID
+func (ls *Lights) Devices() []Device {
+	var ds []Device
+	ls.RangeDevices(func(_ string, d Device) bool {
+		ds = append(ds, d)
+		return true
+	})
+	sort.Slice(ds, func(i, j int) bool { return ds[i].Info().ID < ds[j].Info().ID })
+	return ds
+}
+
+// Device returns the device with id or nil if unknown
+func (m *Manager) Device(id string) Device {
+	return m.lights.Device(id)
+}
+
+// Devices returns an iterator over a snapshot of the known devices
+func (m *Manager) Devices() iter.Seq[Device] {
+	ds := m.lights.Devices()
+	return func(yield func(Device) bool) {
+		for _, d := range ds {
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// AddDevice starts managing d connecting and listening it, lights
+// are added like with Add. It does nothing for known devices
+func (m *Manager) AddDevice(d Device) error {
+	if l, ok := d.(*Light); ok {
+		return m.Add(l)
+	}
+	if m.isClosed() {
+		return errManagerClosed
+	}
+	if _, added := m.lights.LoadOrStoreDevice(d); !added {
+		return nil
+	}
+	if err := m.listen(d); err != nil {
+		m.lights.Delete(d.Info().ID)
+		return err
+	}
+	return nil
+}
+
+// shutdownDevice stops listening d and closes its connection,
+// lights are shut down with Shutdown instead
+func (m *Manager) shutdownDevice(ctx context.Context, d Device) error {
+	if l, ok := d.(*Light); ok {
+		return l.Shutdown(ctx)
+	}
+	m.mu.Lock()
+	ln := m.listeners[d.Info().ID]
+	m.mu.Unlock()
+	var err error
+	if ln != nil {
+		ln.Stop()
+		select {
+		case <-ln.Done():
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	d.Close()
+	return err
+}
@@ -0,0 +1,120 @@
+package yeelight
+
+import (
+	"context"
+	"sync"
+)
+
+// Discoverer finds lights sending them on the returned channel,
+// which is closed when the discovery ends or ctx is done
+type Discoverer interface {
+	Discover(ctx context.Context) (<-chan *Light, error)
+}
+
+// SSDPDiscoverer finds lights with an SSDP M-SEARCH from LocalAddr
+// to MulticastAddr, the default group if empty
+type SSDPDiscoverer struct {
+	LocalAddr     string
+	MulticastAddr string
+}
+
+// Discover implements Discoverer
+func (d SSDPDiscoverer) Discover(ctx context.Context) (<-chan *Light, error) {
+	mcast := d.MulticastAddr
+	if mcast == "" {
+		mcast = mcastAddress
+	}
+	return searchStream(ctx, mcast, d.LocalAddr)
+}
+
+// MultiDiscoverer returns a Discoverer running ds together,
+// lights found by several of them are sent once
+func MultiDiscoverer(ds ...Discoverer) Discoverer {
+	return multiDiscoverer(ds)
+}
+
+type multiDiscoverer []Discoverer
+
+func (md multiDiscoverer) Discover(ctx context.Context) (<-chan *Light, error) {
+	out := make(chan *Light)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+	)
+	for _, d := range md {
+		c, err := d.Discover(ctx)
+		if err != nil {
+			log().Errorf("Discoverer %T failed: %s", d, err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range c {
+				mu.Lock()
+				dup := seen[l.ID]
+				seen[l.ID] = true
+				mu.Unlock()
+				if dup {
+					continue
+				}
+				select {
+				case out <- l:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
+
+// StaticDiscoverer probes a fixed list of addresses like NewLight,
+// for installations with a known inventory. Its lights are sent
+// disconnected for the Manager to connect them
+type StaticDiscoverer []string
+
+// Discover implements Discoverer
+func (sd StaticDiscoverer) Discover(ctx context.Context) (<-chan *Light, error) {
+	out := make(chan *Light)
+	go func() {
+		defer close(out)
+		for _, addr := range sd {
+			l, err := probeLight(addr)
+			if err != nil {
+				log().WithField("address", addr).Errorf("Static light unreachable: %s", err)
+				continue
+			}
+			select {
+			case out <- l:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ScanDiscoverer finds lights scanning CIDR like Scan,
+// sending them disconnected for the Manager to connect them
+type ScanDiscoverer struct {
+	CIDR    string
+	Workers int
+}
+
+// Discover implements Discoverer
+func (sd ScanDiscoverer) Discover(ctx context.Context) (<-chan *Light, error) {
+	return scan(ctx, sd.CIDR, sd.Workers, probeLight)
+}
+
+// WithDiscoverer sets how a Manager finds lights, SSDP by default
+func WithDiscoverer(d Discoverer) Option {
+	return func(c *config) {
+		c.discoverer = d
+	}
+}
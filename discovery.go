@@ -0,0 +1,189 @@
+package yeelight
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiscoveryKind is the kind of a DiscoveryEvent
+type DiscoveryKind int
+
+// Discovery event kinds
+const (
+	LightAdded DiscoveryKind = iota
+	LightUpdated
+	LightRemoved
+)
+
+// DiscoveryEvent reports a change in the lights found, OldAddress
+// is set on updates where the light changed its address
+type DiscoveryEvent struct {
+	Kind       DiscoveryKind
+	Light      *Light
+	OldAddress string
+}
+
+// Discovery keeps a collection of lights up to date combining
+// periodic searches with the SSDP monitor, lights not announced
+// again within their Cache-Control max-age are removed
+type Discovery struct {
+	// Lights found, updated as announcements arrive
+	Lights    *Lights
+	localAddr string
+	interval  time.Duration
+	cfg       config
+	mu        sync.Mutex
+	events    chan DiscoveryEvent
+	dropped   atomic.Uint64
+}
+
+// NewDiscovery returns a Discovery searching from localAddr every interval
+func NewDiscovery(localAddr string, interval time.Duration, opts ...Option) *Discovery {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	lights := NewLights()
+	lights.clock = cfg.clock
+	return &Discovery{
+		cfg:       cfg,
+		Lights:    lights,
+		localAddr: localAddr,
+		interval:  interval,
+		events:    make(chan DiscoveryEvent, 16),
+	}
+}
+
+// Events returns the channel where discovery changes are sent,
+// changes are dropped while it is full, see Dropped
+func (d *Discovery) Events() <-chan DiscoveryEvent {
+	return d.events
+}
+
+// Run discovers lights until ctx is done
+func (d *Discovery) Run(ctx context.Context) error {
+	_, monitor := ssdpProviders()
+	alive := func(h http.Header) {
+		d.found(h)
+	}
+	mon, err := monitor.Monitor(d.cfg.mcastAddr, alive)
+	if err != nil {
+		return err
+	}
+	defer mon.Close()
+	if ipv6Enabled() {
+		if mon6, err := monitorV6(alive); err == nil {
+			defer mon6.Close()
+		} else {
+			d.cfg.getLogger().Errorf("Error monitoring on IPv6: %s", err)
+		}
+	}
+
+	ticker := d.cfg.clock.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		d.search(ctx)
+		d.expire()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+	}
+}
+
+// search runs one M-SEARCH lasting at most half the interval
+func (d *Discovery) search(ctx context.Context) {
+	sctx, cancel := context.WithTimeout(ctx, d.interval/2)
+	defer cancel()
+	sctx, sp := d.cfg.getTracer().Start(sctx, "yeelight.search")
+	defer sp.End()
+	search, _ := ssdpProviders()
+	var headers <-chan http.Header
+	if s, ok := search.(StreamSearchProvider); ok {
+		var err error
+		if headers, err = s.SearchStream(sctx, d.cfg.mcastAddr, searchType, d.localAddr); err != nil {
+			d.cfg.getLogger().Errorf("Error searching: %s", err)
+			sp.RecordError(err)
+			return
+		}
+	} else {
+		headers = bufferedSearch(sctx, search, d.cfg.mcastAddr, d.localAddr)
+	}
+	if ipv6Enabled() {
+		if v6, err := (goSSDP{}).SearchStream(sctx, mcastAddressV6, searchType, ""); err == nil {
+			headers = mergeHeaders(sctx, headers, v6)
+		}
+	}
+	n := 0
+	for h := range headers {
+		n++
+		d.found(h)
+	}
+	sp.SetAttrs(Attr{"yeelight.responses", n})
+}
+
+// found processes an SSDP response or announcement
+func (d *Discovery) found(h http.Header) {
+	light, err := Parse(h)
+	if err != nil {
+		d.cfg.getLogger().Errorf("Invalid SSDP message from %s: %s", h.Get("Location"), err)
+		return
+	}
+	d.mu.Lock()
+	light.setStatus(SSDP)
+	cur, added := d.Lights.LoadOrStore(light)
+	var ev *DiscoveryEvent
+	if added {
+		ev = &DiscoveryEvent{Kind: LightAdded, Light: cur}
+	} else {
+		if cur.Address != light.Address {
+			ev = &DiscoveryEvent{Kind: LightUpdated, Light: cur, OldAddress: cur.Address}
+		} else if cur.Name != light.Name || cur.FW != light.FW || cur.Model != light.Model {
+			ev = &DiscoveryEvent{Kind: LightUpdated, Light: cur}
+		}
+		Copy(cur, light)
+	}
+	cur.seen()
+	d.mu.Unlock()
+	if ev != nil {
+		d.send(*ev)
+	}
+}
+
+// expire removes the lights whose discovery information is stale
+func (d *Discovery) expire() {
+	now := d.cfg.clock.Now()
+	var removed []*Light
+	d.mu.Lock()
+	d.Lights.Range(func(id string, l *Light) bool {
+		if exp := l.DiscoveryExpiresAt(); !exp.IsZero() && now.After(exp) {
+			d.Lights.Delete(id)
+			removed = append(removed, l)
+		}
+		return true
+	})
+	d.mu.Unlock()
+	for _, l := range removed {
+		d.send(DiscoveryEvent{Kind: LightRemoved, Light: l})
+	}
+}
+
+// send queues ev without blocking the SSDP monitor,
+// it is dropped if Events is full
+func (d *Discovery) send(ev DiscoveryEvent) {
+	select {
+	case d.events <- ev:
+	default:
+		n := d.dropped.Add(1)
+		d.cfg.getLogger().WithField("dropped", n).Warnf("Discovery events full, event dropped")
+	}
+}
+
+// Dropped returns the number of events dropped as Events was full
+func (d *Discovery) Dropped() uint64 {
+	return d.dropped.Load()
+}
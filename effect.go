@@ -0,0 +1,55 @@
+package yeelight
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shortest smooth transition accepted by lights
+const minSmooth = 30 * time.Millisecond
+
+// Effect is how a light changes to a new state, Sudden or Smooth
+type Effect time.Duration
+
+// Sudden changes the state at once
+const Sudden Effect = 0
+
+// Smooth changes the state gradually over d, lights require
+// at least 30ms. Smooth(0) is Sudden
+func Smooth(d time.Duration) Effect {
+	return Effect(d)
+}
+
+// Transition returns Sudden for d up to zero and Smooth(d) otherwise
+func Transition(d time.Duration) Effect {
+	if d <= 0 {
+		return Sudden
+	}
+	return Smooth(d)
+}
+
+// Duration returns how long the change lasts
+func (e Effect) Duration() time.Duration {
+	return time.Duration(e)
+}
+
+// String returns "sudden" or "smooth" with the duration
+func (e Effect) String() string {
+	if e == Sudden {
+		return "sudden"
+	}
+	return "smooth " + e.Duration().String()
+}
+
+// Params returns the effect and duration params of commands,
+// smooth effects shorter than 30ms are an error
+func (e Effect) Params() ([]interface{}, error) {
+	d := e.Duration()
+	switch {
+	case e == Sudden:
+		return []interface{}{"sudden", 0}, nil
+	case d < minSmooth:
+		return nil, fmt.Errorf("%w: smooth effect of %v, at least %v", errInvalidParam, d, minSmooth)
+	}
+	return []interface{}{"smooth", int(d / time.Millisecond)}, nil
+}
@@ -0,0 +1,79 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// Buffers commands are encoded into, reused so high rate music
+// mode traffic doesn't allocate a buffer per command. Params are
+// still boxed in Command's interface{} slice
+var cmdBufPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 128)
+		return &b
+	},
+}
+
+// appendCommand appends cmd as JSON terminated by endOfCommand to b,
+// common param types are encoded without going through reflection
+func appendCommand(b []byte, cmd *Command) ([]byte, error) {
+	b = append(b, `{"id":`...)
+	b = strconv.AppendInt(b, int64(cmd.ID), 10)
+	b = append(b, `,"method":`...)
+	b = appendString(b, cmd.Method)
+	b = append(b, `,"params":[`...)
+	for i, p := range cmd.Params {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		var err error
+		if b, err = appendParam(b, p); err != nil {
+			return b, err
+		}
+	}
+	b = append(b, "]}"...)
+	return append(b, endOfCommand...), nil
+}
+
+func appendParam(b []byte, p interface{}) ([]byte, error) {
+	switch v := p.(type) {
+	case int:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(b, v, 10), nil
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10), nil
+	case string:
+		return appendString(b, v), nil
+	case bool:
+		return strconv.AppendBool(b, v), nil
+	}
+	j, err := json.Marshal(p)
+	if err != nil {
+		return b, err
+	}
+	return append(b, j...), nil
+}
+
+// appendString appends s quoted, strings needing escapes
+// are left to encoding/json
+func appendString(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			j, _ := json.Marshal(s)
+			return append(b, j...)
+		}
+	}
+	b = append(b, '"')
+	b = append(b, s...)
+	return append(b, '"')
+}
@@ -0,0 +1,61 @@
+package yeelight
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by lights in command results, use errors.Is
+// on the error returned by Result.Err to branch on them
+var (
+	ErrDevice             = errors.New("light returned an error")
+	ErrInvalidCommand     = errors.New("invalid command")
+	ErrMethodNotSupported = errors.New("method not supported")
+	ErrQuotaExceeded      = errors.New("client quota exceeded")
+)
+
+// Errors returned waiting results, see WaitResultCtx
+var (
+	ErrTimeout          = errors.New("Timeout waiting result")
+	ErrMismatchedResult = errors.New("Result does not fit the request")
+)
+
+// ErrLANControlDisabled is returned connecting to lights refusing
+// connections, usually because LAN control is disabled in the Yeelight
+// app. Users should be prompted to enable it, or WithMiIO can be used
+var ErrLANControlDisabled = errors.New("connection refused, is LAN control enabled?")
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("yeelight error %d: %s", e.Code, e.Message)
+}
+
+// Is matches the light error against the exported sentinel errors,
+// lights use code -1 for most failures so the message is checked too
+func (e *Error) Is(target error) bool {
+	msg := strings.ToLower(e.Message)
+	if e.Code == errCodeTimeout {
+		return target == ErrTimeout
+	}
+	switch target {
+	case ErrDevice:
+		return true
+	case ErrQuotaExceeded:
+		return strings.Contains(msg, "quota")
+	case ErrMethodNotSupported:
+		return strings.Contains(msg, "unsupported method") ||
+			strings.Contains(msg, "method not supported")
+	case ErrInvalidCommand:
+		return strings.Contains(msg, "invalid command")
+	}
+	return false
+}
+
+// Err returns the error reported by the light or nil on success
+func (r *Result) Err() error {
+	if r.Error == nil {
+		return nil
+	}
+	return r.Error
+}
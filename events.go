@@ -0,0 +1,139 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EventKind is the kind of an Event
+type EventKind int
+
+// Event kinds
+const (
+	EventCommandSent EventKind = iota
+	EventResult
+	EventNotification
+	EventStatus
+	EventReconnect
+	EventDiscovery
+	EventAddressChanged
+	EventDropped
+	EventStale
+	EventUnmatched
+	EventUnknownNotification
+	EventResync
+)
+
+var eventKindNames = map[EventKind]string{
+	EventCommandSent:         "command",
+	EventResult:              "result",
+	EventNotification:        "notification",
+	EventStatus:              "status",
+	EventReconnect:           "reconnect",
+	EventDiscovery:           "discovery",
+	EventAddressChanged:      "address_changed",
+	EventDropped:             "dropped",
+	EventStale:               "stale",
+	EventUnmatched:           "unmatched",
+	EventUnknownNotification: "unknown_notification",
+	EventResync:              "resync",
+}
+
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	return eventKindNames[k]
+}
+
+// MarshalJSON encodes the event kind as its name
+func (k EventKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// Event is a machine-consumable record of something that happened
+// to a light, only the fields relevant to Kind are set
+type Event struct {
+	Kind         EventKind     `json:"kind"`
+	DevID        string        `json:"id"`
+	At           time.Time     `json:"at"`
+	Command      *Command      `json:"command,omitempty"`
+	Result       *Result       `json:"result,omitempty"`
+	Notification *Notification `json:"notification,omitempty"`
+	Status       *StatusChange `json:"status,omitempty"`
+	Address      string        `json:"address,omitempty"`
+	OldAddress   string        `json:"old_address,omitempty"`
+	Dropped      uint64        `json:"dropped,omitempty"`
+}
+
+// EventHandler receives the events of lights, HandleEvent
+// must not block as it runs on lights' goroutines
+type EventHandler interface {
+	HandleEvent(e Event)
+}
+
+// EventHandlerFunc adapts a function to an EventHandler
+type EventHandlerFunc func(e Event)
+
+// HandleEvent calls f(e)
+func (f EventHandlerFunc) HandleEvent(e Event) {
+	f(e)
+}
+
+// EventChan returns a handler sending events to c,
+// events are dropped if c is not ready
+func EventChan(c chan<- Event) EventHandler {
+	return EventHandlerFunc(func(e Event) {
+		select {
+		case c <- e:
+		default:
+		}
+	})
+}
+
+// WithEventHandler sets the handler receiving events
+func WithEventHandler(h EventHandler) Option {
+	return func(c *config) {
+		c.events = h
+	}
+}
+
+// eventFanout sends events to several handlers
+type eventFanout struct {
+	mu sync.RWMutex
+	hs []EventHandler
+}
+
+func (f *eventFanout) add(h EventHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hs = append(f.hs, h)
+}
+
+// HandleEvent implements EventHandler
+func (f *eventFanout) HandleEvent(e Event) {
+	f.mu.RLock()
+	hs := f.hs
+	f.mu.RUnlock()
+	for _, h := range hs {
+		h.HandleEvent(e)
+	}
+}
+
+// AddEventHandler adds h to the handlers receiving the events of
+// Manager's lights, along with the one set by WithEventHandler
+func (m *Manager) AddEventHandler(h EventHandler) {
+	m.handlers.add(h)
+}
+
+// emit sends e to light's event handler if any
+func (l *Light) emit(e Event) {
+	h := l.cfg.events
+	if h == nil {
+		return
+	}
+	e.DevID = l.ID
+	if e.At.IsZero() {
+		e.At = l.now()
+	}
+	h.HandleEvent(e)
+}
@@ -0,0 +1,8 @@
+package yeelight
+
+import "time"
+
+// ReconnectDelay exposes the backoff of p to the tests
+func ReconnectDelay(p ReconnectPolicy, attempt int) time.Duration {
+	return p.delay(attempt)
+}
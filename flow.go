@@ -0,0 +1,236 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlowAction is what the light does when a color flow ends
+type FlowAction int
+
+// Flow end actions
+const (
+	// FlowRecover returns to the state before the flow
+	FlowRecover FlowAction = 0
+	// FlowStay keeps the state of the last transition
+	FlowStay FlowAction = 1
+	// FlowOff turns the light off
+	FlowOff FlowAction = 2
+)
+
+// FlowMode is the kind of a flow transition
+type FlowMode int
+
+// Flow transition modes
+const (
+	FlowColor FlowMode = 1
+	FlowCT    FlowMode = 2
+	FlowSleep FlowMode = 7
+)
+
+// Minimum duration of a flow transition
+const minFlowDuration = 50 * time.Millisecond
+
+// FlowTransition is a step of a color flow, in JSON its
+// duration is in milliseconds like in flow expressions
+type FlowTransition struct {
+	// Duration is at least 50ms, lights only take milliseconds
+	Duration time.Duration
+	Mode     FlowMode
+	// Value is RGB for FlowColor, CT for FlowCT and ignored for FlowSleep
+	Value int
+	// Bright is the brightness (1-100), -1 keeps the current one
+	Bright int
+}
+
+// flowTransitionJSON is the JSON encoding of FlowTransition
+type flowTransitionJSON struct {
+	Duration int64    `json:"duration"`
+	Mode     FlowMode `json:"mode"`
+	Value    int      `json:"value"`
+	Bright   int      `json:"bright"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (t FlowTransition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(flowTransitionJSON{t.Duration.Milliseconds(), t.Mode, t.Value, t.Bright})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *FlowTransition) UnmarshalJSON(data []byte) error {
+	var j flowTransitionJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*t = FlowTransition{time.Duration(j.Duration) * time.Millisecond, j.Mode, j.Value, j.Bright}
+	return nil
+}
+
+// Flow is a color flow run by the light itself
+type Flow struct {
+	// Count is the number of transitions to run, 0 runs forever
+	Count       int              `json:"count"`
+	Action      FlowAction       `json:"action"`
+	Transitions []FlowTransition `json:"transitions"`
+}
+
+// Expression returns the flow expression of start_cf
+func (f *Flow) Expression() (string, error) {
+	if len(f.Transitions) == 0 {
+		return "", fmt.Errorf("%w: empty flow", errInvalidParam)
+	}
+	parts := make([]string, 0, len(f.Transitions))
+	for _, t := range f.Transitions {
+		if t.Duration < minFlowDuration {
+			return "", fmt.Errorf("%w: flow transition of %s, minimum is %s",
+				errInvalidParam, t.Duration, minFlowDuration)
+		}
+		parts = append(parts, fmt.Sprintf("%d,%d,%d,%d", t.Duration.Milliseconds(), t.Mode, t.Value, t.Bright))
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// ParseFlowExpression parses the transitions of a start_cf flow
+// expression, "duration,mode,value,bright" repeated
+func ParseFlowExpression(expr string) ([]FlowTransition, error) {
+	fields := strings.Split(strings.Join(strings.Fields(expr), ""), ",")
+	if len(fields)%4 != 0 {
+		return nil, fmt.Errorf("%w: flow expression of %d values, not tuples of 4", errInvalidParam, len(fields))
+	}
+	var transitions []FlowTransition
+	for i := 0; i < len(fields); i += 4 {
+		var v [4]int
+		for j := range v {
+			n, err := strconv.Atoi(fields[i+j])
+			if err != nil {
+				return nil, fmt.Errorf("%w: flow value %q", errInvalidParam, fields[i+j])
+			}
+			v[j] = n
+		}
+		t := FlowTransition{
+			Duration: time.Duration(v[0]) * time.Millisecond,
+			Mode:     FlowMode(v[1]),
+			Value:    v[2],
+			Bright:   v[3],
+		}
+		if t.Duration < minFlowDuration {
+			return nil, fmt.Errorf("%w: flow transition of %s, minimum is %s",
+				errInvalidParam, t.Duration, minFlowDuration)
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions, nil
+}
+
+// ParseFlowParams decodes the flow_params property, the count and
+// action of the flow followed by its expression
+func ParseFlowParams(params string) (*Flow, error) {
+	count, rest, ok := strings.Cut(params, ",")
+	action, expr, ok2 := strings.Cut(rest, ",")
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("%w: flow params %q", errInvalidParam, params)
+	}
+	c, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return nil, fmt.Errorf("%w: flow count %q", errInvalidParam, count)
+	}
+	a, err := strconv.Atoi(strings.TrimSpace(action))
+	if err != nil || FlowAction(a) < FlowRecover || FlowAction(a) > FlowOff {
+		return nil, fmt.Errorf("%w: flow action %q", errInvalidParam, action)
+	}
+	transitions, err := ParseFlowExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Flow{Count: c, Action: FlowAction(a), Transitions: transitions}, nil
+}
+
+// runningFlow decodes params if flowing is 1
+func runningFlow(flowing int, params string) *Flow {
+	if flowing != 1 || params == "" {
+		return nil
+	}
+	f, err := ParseFlowParams(params)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// StartColorFlow starts flow on the light
+func (l *Light) StartColorFlow(f *Flow) (int32, error) {
+	expr, err := f.Expression()
+	if err != nil {
+		return 0, err
+	}
+	if f.Action < FlowRecover || f.Action > FlowOff {
+		return 0, fmt.Errorf("%w: flow action %d", errInvalidParam, f.Action)
+	}
+	return l.SendCommand("start_cf", f.Count, int(f.Action), expr)
+}
+
+// StopColorFlow stops the running color flow
+func (l *Light) StopColorFlow() (int32, error) {
+	return l.SendCommand("stop_cf", "")
+}
+
+// Flash returns a flow flashing rgb times then doing action
+func Flash(rgb uint32, times int, action FlowAction) *Flow {
+	return &Flow{
+		Count:  times * 2,
+		Action: action,
+		Transitions: []FlowTransition{
+			{Duration: 250 * time.Millisecond, Mode: FlowColor, Value: int(rgb), Bright: 100},
+			{Duration: 250 * time.Millisecond, Mode: FlowColor, Value: int(rgb), Bright: 1},
+		},
+	}
+}
+
+// Pulse returns a flow slowly pulsing rgb forever
+func Pulse(rgb uint32, period time.Duration) *Flow {
+	half := period / 2
+	if half < minFlowDuration {
+		half = minFlowDuration
+	}
+	return &Flow{
+		Count:  0,
+		Action: FlowRecover,
+		Transitions: []FlowTransition{
+			{Duration: half, Mode: FlowColor, Value: int(rgb), Bright: 100},
+			{Duration: half, Mode: FlowColor, Value: int(rgb), Bright: 10},
+		},
+	}
+}
+
+// Sunrise returns a flow going from a dim warm light to bright
+// daylight in duration, staying on at the end
+func Sunrise(duration time.Duration) *Flow {
+	step := duration / 3
+	return &Flow{
+		Count:  3,
+		Action: FlowStay,
+		Transitions: []FlowTransition{
+			{Duration: minFlowDuration, Mode: FlowColor, Value: 0xff4d00, Bright: 1},
+			{Duration: step, Mode: FlowColor, Value: 0xffb400, Bright: 10},
+			{Duration: step * 2, Mode: FlowCT, Value: 5000, Bright: 100},
+		},
+	}
+}
+
+// Sunset returns a flow dimming to a warm light in duration
+// and turning off at the end
+func Sunset(duration time.Duration) *Flow {
+	step := duration / 3
+	return &Flow{
+		Count:  3,
+		Action: FlowOff,
+		Transitions: []FlowTransition{
+			{Duration: step, Mode: FlowCT, Value: 2700, Bright: 50},
+			{Duration: step, Mode: FlowColor, Value: 0xff6400, Bright: 10},
+			{Duration: step, Mode: FlowColor, Value: 0xff2800, Bright: 1},
+		},
+	}
+}
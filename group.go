@@ -0,0 +1,30 @@
+package yeelight
+
+import "sort"
+
+// Group is a named set of lights commanded together
+type Group struct {
+	Name   string
+	Lights []*Light
+}
+
+// NewGroup returns a group name of lights
+func NewGroup(name string, lights ...*Light) *Group {
+	return &Group{Name: name, Lights: lights}
+}
+
+// Add adds lights to the group
+func (g *Group) Add(lights ...*Light) {
+	g.Lights = append(g.Lights, lights...)
+}
+
+// list returns the lights of the collection sorted by ID
+func (ls *Lights) list() []*Light {
+	var list []*Light
+	ls.Range(func(_ string, l *Light) bool {
+		list = append(list, l)
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
@@ -0,0 +1,8 @@
+// Package grpcapi serves a yeelight.Manager over gRPC, see
+// yeelight.proto for the service definition.
+//
+// The generated code is checked in, regenerate it after changing
+// yeelight.proto with protoc-gen-go and protoc-gen-go-grpc
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative yeelight.proto
@@ -0,0 +1,197 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Default time SetState waits for each command
+const defaultTimeout = 5 * time.Second
+
+// Server implements the Yeelight service for the lights of a Manager.
+// It is also an EventHandler fanning out events to StreamEvents
+// clients, pass it to the Manager with yeelight.WithEventHandler
+type Server struct {
+	UnimplementedYeelightServer
+	m *yeelight.Manager
+	// Timeout bounds how long SetState waits for each command
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	streams map[chan *Event]bool
+}
+
+// NewServer returns a Server for m's lights, register it
+// with RegisterYeelightServer
+func NewServer(m *yeelight.Manager) *Server {
+	return &Server{m: m, Timeout: defaultTimeout, streams: make(map[chan *Event]bool)}
+}
+
+// ListLights implements YeelightServer
+func (s *Server) ListLights(context.Context, *ListLightsRequest) (*ListLightsResponse, error) {
+	resp := &ListLightsResponse{}
+	for l := range s.m.All() {
+		resp.Lights = append(resp.Lights, state(l))
+	}
+	sort.Slice(resp.Lights, func(i, j int) bool { return resp.Lights[i].Id < resp.Lights[j].Id })
+	return resp, nil
+}
+
+// GetState implements YeelightServer
+func (s *Server) GetState(_ context.Context, req *GetStateRequest) (*LightState, error) {
+	l := s.m.Get(req.GetId())
+	if l == nil {
+		return nil, status.Errorf(codes.NotFound, "unknown light %q", req.GetId())
+	}
+	return state(l), nil
+}
+
+// SetState implements YeelightServer
+func (s *Server) SetState(ctx context.Context, req *SetStateRequest) (*LightState, error) {
+	l := s.m.Get(req.GetId())
+	if l == nil {
+		return nil, status.Errorf(codes.NotFound, "unknown light %q", req.GetId())
+	}
+	effect, duration := "sudden", 0
+	if req.GetDuration() > 0 {
+		effect, duration = "smooth", int(req.GetDuration())
+	}
+	var cmds [][]interface{}
+	if req.Power != nil {
+		p := "off"
+		if req.GetPower() {
+			p = "on"
+		}
+		cmds = append(cmds, []interface{}{"set_power", p, effect, duration})
+	}
+	if req.Ct != nil {
+		cmds = append(cmds, []interface{}{"set_ct_abx", int(req.GetCt()), effect, duration})
+	}
+	if req.Rgb != nil {
+		cmds = append(cmds, []interface{}{"set_rgb", int(req.GetRgb()), effect, duration})
+	}
+	if req.Bright != nil {
+		cmds = append(cmds, []interface{}{"set_bright", int(req.GetBright()), effect, duration})
+	}
+	for _, c := range cmds {
+		cctx, cancel := context.WithTimeout(ctx, s.Timeout)
+		_, err := l.Call(cctx, c[0].(string), c[1:]...)
+		cancel()
+		if err != nil {
+			return nil, status.Error(code(err), err.Error())
+		}
+	}
+	return state(l), nil
+}
+
+// StreamEvents implements YeelightServer
+func (s *Server) StreamEvents(req *StreamEventsRequest, stream grpc.ServerStreamingServer[Event]) error {
+	ids := make(map[string]bool, len(req.GetIds()))
+	for _, id := range req.GetIds() {
+		ids[id] = true
+	}
+	c := make(chan *Event, 64)
+	s.mu.Lock()
+	s.streams[c] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, c)
+		s.mu.Unlock()
+	}()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-c:
+			if len(ids) > 0 && !ids[e.Id] {
+				continue
+			}
+			if err := stream.Send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// HandleEvent implements yeelight.EventHandler, events
+// are dropped for clients not keeping up
+func (s *Server) HandleEvent(e yeelight.Event) {
+	ev := &Event{Id: e.DevID, Kind: e.Kind.String(), AtUnixNano: e.At.UnixNano()}
+	if e.Notification != nil {
+		ev.Props = make(map[string]string, len(e.Notification.Params))
+		for k, v := range e.Notification.Params {
+			ev.Props[k] = propString(v)
+		}
+	}
+	if e.Status != nil {
+		ev.Status = e.Status.To.String()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.streams {
+		select {
+		case c <- ev:
+		default:
+		}
+	}
+}
+
+// state converts light to its message
+func state(l *yeelight.Light) *LightState {
+	j := l.JSON()
+	return &LightState{
+		Id:           j.ID,
+		Name:         j.Name,
+		Address:      j.Address,
+		Model:        j.Model,
+		Status:       j.Status.String(),
+		Power:        j.Power.IsOn(),
+		Bright:       int32(j.Bright),
+		ColorMode:    int32(j.ColorMode),
+		Ct:           int32(j.CT),
+		Rgb:          int32(j.RGB),
+		Hue:          int32(j.Hue),
+		Sat:          int32(j.Sat),
+		Capabilities: j.Capabilities,
+	}
+}
+
+// code maps command errors to gRPC codes
+func code(err error) codes.Code {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, yeelight.ErrQuotaExceeded):
+		return codes.ResourceExhausted
+	case errors.Is(err, yeelight.ErrMethodNotSupported):
+		return codes.Unimplemented
+	case errors.Is(err, yeelight.ErrInvalidCommand):
+		return codes.InvalidArgument
+	}
+	return codes.Unavailable
+}
+
+// propString formats a notified value, numbers without exponent
+func propString(v interface{}) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case string:
+		return n
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
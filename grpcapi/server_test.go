@@ -0,0 +1,72 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/grpcapi"
+	"github.com/pulento/yeelight/yeelighttest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// serve returns a client of a Server for a Manager knowing b
+func serve(t *testing.T, b *yeelighttest.Bulb) grpcapi.YeelightClient {
+	t.Helper()
+	m := yeelight.NewManager("", yeelight.WithDiscoverer(yeelight.StaticDiscoverer{b.Addr()}))
+	t.Cleanup(func() { m.Close(context.Background()) })
+	s := grpcapi.NewServer(m)
+	m.AddEventHandler(s)
+	if err := m.Discover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ln := bufconn.Listen(1 << 16)
+	gs := grpc.NewServer()
+	grpcapi.RegisterYeelightServer(gs, s)
+	go gs.Serve(ln)
+	t.Cleanup(gs.Stop)
+	cc, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return grpcapi.NewYeelightClient(cc)
+}
+
+func TestListAndSetState(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	c := serve(t, b)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	list, err := c.ListLights(ctx, &grpcapi.ListLightsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetLights()) != 1 || list.GetLights()[0].GetId() != b.Addr() {
+		t.Fatalf("lights = %v, want %s", list.GetLights(), b.Addr())
+	}
+	_, err = c.SetState(ctx, &grpcapi.SetStateRequest{Id: b.Addr(), Power: proto.Bool(false), Bright: proto.Int32(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Prop("power") != "off" || b.Prop("bright") != "20" {
+		t.Errorf("bulb power %s bright %s, want off 20", b.Prop("power"), b.Prop("bright"))
+	}
+
+	_, err = c.GetState(ctx, &grpcapi.GetStateRequest{Id: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetState of unknown light: %v, want NotFound", err)
+	}
+}
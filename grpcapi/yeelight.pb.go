@@ -0,0 +1,618 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: yeelight.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListLightsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsRequest) Reset() {
+	*x = ListLightsRequest{}
+	mi := &file_yeelight_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsRequest) ProtoMessage() {}
+
+func (x *ListLightsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsRequest.ProtoReflect.Descriptor instead.
+func (*ListLightsRequest) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{0}
+}
+
+type ListLightsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lights        []*LightState          `protobuf:"bytes,1,rep,name=lights,proto3" json:"lights,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLightsResponse) Reset() {
+	*x = ListLightsResponse{}
+	mi := &file_yeelight_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLightsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLightsResponse) ProtoMessage() {}
+
+func (x *ListLightsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLightsResponse.ProtoReflect.Descriptor instead.
+func (*ListLightsResponse) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListLightsResponse) GetLights() []*LightState {
+	if x != nil {
+		return x.Lights
+	}
+	return nil
+}
+
+type GetStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStateRequest) Reset() {
+	*x = GetStateRequest{}
+	mi := &file_yeelight_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStateRequest) ProtoMessage() {}
+
+func (x *GetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStateRequest.ProtoReflect.Descriptor instead.
+func (*GetStateRequest) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetStateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type LightState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Power         bool                   `protobuf:"varint,6,opt,name=power,proto3" json:"power,omitempty"`
+	Bright        int32                  `protobuf:"varint,7,opt,name=bright,proto3" json:"bright,omitempty"`
+	ColorMode     int32                  `protobuf:"varint,8,opt,name=color_mode,json=colorMode,proto3" json:"color_mode,omitempty"`
+	Ct            int32                  `protobuf:"varint,9,opt,name=ct,proto3" json:"ct,omitempty"`
+	Rgb           int32                  `protobuf:"varint,10,opt,name=rgb,proto3" json:"rgb,omitempty"`
+	Hue           int32                  `protobuf:"varint,11,opt,name=hue,proto3" json:"hue,omitempty"`
+	Sat           int32                  `protobuf:"varint,12,opt,name=sat,proto3" json:"sat,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,13,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LightState) Reset() {
+	*x = LightState{}
+	mi := &file_yeelight_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LightState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LightState) ProtoMessage() {}
+
+func (x *LightState) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LightState.ProtoReflect.Descriptor instead.
+func (*LightState) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LightState) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *LightState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LightState) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *LightState) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *LightState) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LightState) GetPower() bool {
+	if x != nil {
+		return x.Power
+	}
+	return false
+}
+
+func (x *LightState) GetBright() int32 {
+	if x != nil {
+		return x.Bright
+	}
+	return 0
+}
+
+func (x *LightState) GetColorMode() int32 {
+	if x != nil {
+		return x.ColorMode
+	}
+	return 0
+}
+
+func (x *LightState) GetCt() int32 {
+	if x != nil {
+		return x.Ct
+	}
+	return 0
+}
+
+func (x *LightState) GetRgb() int32 {
+	if x != nil {
+		return x.Rgb
+	}
+	return 0
+}
+
+func (x *LightState) GetHue() int32 {
+	if x != nil {
+		return x.Hue
+	}
+	return 0
+}
+
+func (x *LightState) GetSat() int32 {
+	if x != nil {
+		return x.Sat
+	}
+	return 0
+}
+
+func (x *LightState) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type SetStateRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Power  *bool                  `protobuf:"varint,2,opt,name=power,proto3,oneof" json:"power,omitempty"`
+	Bright *int32                 `protobuf:"varint,3,opt,name=bright,proto3,oneof" json:"bright,omitempty"`
+	Ct     *int32                 `protobuf:"varint,4,opt,name=ct,proto3,oneof" json:"ct,omitempty"`
+	Rgb    *int32                 `protobuf:"varint,5,opt,name=rgb,proto3,oneof" json:"rgb,omitempty"`
+	// duration of the transition in milliseconds, 0 is sudden
+	Duration      int32 `protobuf:"varint,6,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetStateRequest) Reset() {
+	*x = SetStateRequest{}
+	mi := &file_yeelight_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetStateRequest) ProtoMessage() {}
+
+func (x *SetStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetStateRequest.ProtoReflect.Descriptor instead.
+func (*SetStateRequest) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetStateRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SetStateRequest) GetPower() bool {
+	if x != nil && x.Power != nil {
+		return *x.Power
+	}
+	return false
+}
+
+func (x *SetStateRequest) GetBright() int32 {
+	if x != nil && x.Bright != nil {
+		return *x.Bright
+	}
+	return 0
+}
+
+func (x *SetStateRequest) GetCt() int32 {
+	if x != nil && x.Ct != nil {
+		return *x.Ct
+	}
+	return 0
+}
+
+func (x *SetStateRequest) GetRgb() int32 {
+	if x != nil && x.Rgb != nil {
+		return *x.Rgb
+	}
+	return 0
+}
+
+func (x *SetStateRequest) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ids filters the lights whose events are sent, all if empty
+	Ids           []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_yeelight_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamEventsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type Event struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind       string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	AtUnixNano int64                  `protobuf:"varint,3,opt,name=at_unix_nano,json=atUnixNano,proto3" json:"at_unix_nano,omitempty"`
+	// props are the properties notified by the light
+	Props map[string]string `protobuf:"bytes,4,rep,name=props,proto3" json:"props,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// status is the new status of status events
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_yeelight_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_yeelight_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_yeelight_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Event) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Event) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Event) GetAtUnixNano() int64 {
+	if x != nil {
+		return x.AtUnixNano
+	}
+	return 0
+}
+
+func (x *Event) GetProps() map[string]string {
+	if x != nil {
+		return x.Props
+	}
+	return nil
+}
+
+func (x *Event) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+var File_yeelight_proto protoreflect.FileDescriptor
+
+const file_yeelight_proto_rawDesc = "" +
+	"\n" +
+	"\x0eyeelight.proto\x12\byeelight\"\x13\n" +
+	"\x11ListLightsRequest\"B\n" +
+	"\x12ListLightsResponse\x12,\n" +
+	"\x06lights\x18\x01 \x03(\v2\x14.yeelight.LightStateR\x06lights\"!\n" +
+	"\x0fGetStateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xaf\x02\n" +
+	"\n" +
+	"LightState\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x14\n" +
+	"\x05power\x18\x06 \x01(\bR\x05power\x12\x16\n" +
+	"\x06bright\x18\a \x01(\x05R\x06bright\x12\x1d\n" +
+	"\n" +
+	"color_mode\x18\b \x01(\x05R\tcolorMode\x12\x0e\n" +
+	"\x02ct\x18\t \x01(\x05R\x02ct\x12\x10\n" +
+	"\x03rgb\x18\n" +
+	" \x01(\x05R\x03rgb\x12\x10\n" +
+	"\x03hue\x18\v \x01(\x05R\x03hue\x12\x10\n" +
+	"\x03sat\x18\f \x01(\x05R\x03sat\x12\"\n" +
+	"\fcapabilities\x18\r \x03(\tR\fcapabilities\"\xc5\x01\n" +
+	"\x0fSetStateRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\x05power\x18\x02 \x01(\bH\x00R\x05power\x88\x01\x01\x12\x1b\n" +
+	"\x06bright\x18\x03 \x01(\x05H\x01R\x06bright\x88\x01\x01\x12\x13\n" +
+	"\x02ct\x18\x04 \x01(\x05H\x02R\x02ct\x88\x01\x01\x12\x15\n" +
+	"\x03rgb\x18\x05 \x01(\x05H\x03R\x03rgb\x88\x01\x01\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\x05R\bdurationB\b\n" +
+	"\x06_powerB\t\n" +
+	"\a_brightB\x05\n" +
+	"\x03_ctB\x06\n" +
+	"\x04_rgb\"'\n" +
+	"\x13StreamEventsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"\xd1\x01\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12 \n" +
+	"\fat_unix_nano\x18\x03 \x01(\x03R\n" +
+	"atUnixNano\x120\n" +
+	"\x05props\x18\x04 \x03(\v2\x1a.yeelight.Event.PropsEntryR\x05props\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x1a8\n" +
+	"\n" +
+	"PropsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\x8f\x02\n" +
+	"\bYeelight\x12G\n" +
+	"\n" +
+	"ListLights\x12\x1b.yeelight.ListLightsRequest\x1a\x1c.yeelight.ListLightsResponse\x12;\n" +
+	"\bGetState\x12\x19.yeelight.GetStateRequest\x1a\x14.yeelight.LightState\x12;\n" +
+	"\bSetState\x12\x19.yeelight.SetStateRequest\x1a\x14.yeelight.LightState\x12@\n" +
+	"\fStreamEvents\x12\x1d.yeelight.StreamEventsRequest\x1a\x0f.yeelight.Event0\x01B%Z#github.com/pulento/yeelight/grpcapib\x06proto3"
+
+var (
+	file_yeelight_proto_rawDescOnce sync.Once
+	file_yeelight_proto_rawDescData []byte
+)
+
+func file_yeelight_proto_rawDescGZIP() []byte {
+	file_yeelight_proto_rawDescOnce.Do(func() {
+		file_yeelight_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_yeelight_proto_rawDesc), len(file_yeelight_proto_rawDesc)))
+	})
+	return file_yeelight_proto_rawDescData
+}
+
+var file_yeelight_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_yeelight_proto_goTypes = []any{
+	(*ListLightsRequest)(nil),   // 0: yeelight.ListLightsRequest
+	(*ListLightsResponse)(nil),  // 1: yeelight.ListLightsResponse
+	(*GetStateRequest)(nil),     // 2: yeelight.GetStateRequest
+	(*LightState)(nil),          // 3: yeelight.LightState
+	(*SetStateRequest)(nil),     // 4: yeelight.SetStateRequest
+	(*StreamEventsRequest)(nil), // 5: yeelight.StreamEventsRequest
+	(*Event)(nil),               // 6: yeelight.Event
+	nil,                         // 7: yeelight.Event.PropsEntry
+}
+var file_yeelight_proto_depIdxs = []int32{
+	3, // 0: yeelight.ListLightsResponse.lights:type_name -> yeelight.LightState
+	7, // 1: yeelight.Event.props:type_name -> yeelight.Event.PropsEntry
+	0, // 2: yeelight.Yeelight.ListLights:input_type -> yeelight.ListLightsRequest
+	2, // 3: yeelight.Yeelight.GetState:input_type -> yeelight.GetStateRequest
+	4, // 4: yeelight.Yeelight.SetState:input_type -> yeelight.SetStateRequest
+	5, // 5: yeelight.Yeelight.StreamEvents:input_type -> yeelight.StreamEventsRequest
+	1, // 6: yeelight.Yeelight.ListLights:output_type -> yeelight.ListLightsResponse
+	3, // 7: yeelight.Yeelight.GetState:output_type -> yeelight.LightState
+	3, // 8: yeelight.Yeelight.SetState:output_type -> yeelight.LightState
+	6, // 9: yeelight.Yeelight.StreamEvents:output_type -> yeelight.Event
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_yeelight_proto_init() }
+func file_yeelight_proto_init() {
+	if File_yeelight_proto != nil {
+		return
+	}
+	file_yeelight_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_yeelight_proto_rawDesc), len(file_yeelight_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_yeelight_proto_goTypes,
+		DependencyIndexes: file_yeelight_proto_depIdxs,
+		MessageInfos:      file_yeelight_proto_msgTypes,
+	}.Build()
+	File_yeelight_proto = out.File
+	file_yeelight_proto_goTypes = nil
+	file_yeelight_proto_depIdxs = nil
+}
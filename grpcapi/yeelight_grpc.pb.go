@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: yeelight.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Yeelight_ListLights_FullMethodName   = "/yeelight.Yeelight/ListLights"
+	Yeelight_GetState_FullMethodName     = "/yeelight.Yeelight/GetState"
+	Yeelight_SetState_FullMethodName     = "/yeelight.Yeelight/SetState"
+	Yeelight_StreamEvents_FullMethodName = "/yeelight.Yeelight/StreamEvents"
+)
+
+// YeelightClient is the client API for Yeelight service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Yeelight controls the lights known by a yeelight.Manager
+type YeelightClient interface {
+	ListLights(ctx context.Context, in *ListLightsRequest, opts ...grpc.CallOption) (*ListLightsResponse, error)
+	GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*LightState, error)
+	// SetState changes the fields set, in order power, color and brightness
+	SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*LightState, error)
+	// StreamEvents sends lights' events until the client cancels
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type yeelightClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewYeelightClient(cc grpc.ClientConnInterface) YeelightClient {
+	return &yeelightClient{cc}
+}
+
+func (c *yeelightClient) ListLights(ctx context.Context, in *ListLightsRequest, opts ...grpc.CallOption) (*ListLightsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLightsResponse)
+	err := c.cc.Invoke(ctx, Yeelight_ListLights_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *yeelightClient) GetState(ctx context.Context, in *GetStateRequest, opts ...grpc.CallOption) (*LightState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LightState)
+	err := c.cc.Invoke(ctx, Yeelight_GetState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *yeelightClient) SetState(ctx context.Context, in *SetStateRequest, opts ...grpc.CallOption) (*LightState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LightState)
+	err := c.cc.Invoke(ctx, Yeelight_SetState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *yeelightClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Yeelight_ServiceDesc.Streams[0], Yeelight_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Yeelight_StreamEventsClient = grpc.ServerStreamingClient[Event]
+
+// YeelightServer is the server API for Yeelight service.
+// All implementations must embed UnimplementedYeelightServer
+// for forward compatibility.
+//
+// Yeelight controls the lights known by a yeelight.Manager
+type YeelightServer interface {
+	ListLights(context.Context, *ListLightsRequest) (*ListLightsResponse, error)
+	GetState(context.Context, *GetStateRequest) (*LightState, error)
+	// SetState changes the fields set, in order power, color and brightness
+	SetState(context.Context, *SetStateRequest) (*LightState, error)
+	// StreamEvents sends lights' events until the client cancels
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedYeelightServer()
+}
+
+// UnimplementedYeelightServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedYeelightServer struct{}
+
+func (UnimplementedYeelightServer) ListLights(context.Context, *ListLightsRequest) (*ListLightsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLights not implemented")
+}
+func (UnimplementedYeelightServer) GetState(context.Context, *GetStateRequest) (*LightState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetState not implemented")
+}
+func (UnimplementedYeelightServer) SetState(context.Context, *SetStateRequest) (*LightState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetState not implemented")
+}
+func (UnimplementedYeelightServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedYeelightServer) mustEmbedUnimplementedYeelightServer() {}
+func (UnimplementedYeelightServer) testEmbeddedByValue()                  {}
+
+// UnsafeYeelightServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to YeelightServer will
+// result in compilation errors.
+type UnsafeYeelightServer interface {
+	mustEmbedUnimplementedYeelightServer()
+}
+
+func RegisterYeelightServer(s grpc.ServiceRegistrar, srv YeelightServer) {
+	// If the following call pancis, it indicates UnimplementedYeelightServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Yeelight_ServiceDesc, srv)
+}
+
+func _Yeelight_ListLights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(YeelightServer).ListLights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Yeelight_ListLights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(YeelightServer).ListLights(ctx, req.(*ListLightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Yeelight_GetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(YeelightServer).GetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Yeelight_GetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(YeelightServer).GetState(ctx, req.(*GetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Yeelight_SetState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(YeelightServer).SetState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Yeelight_SetState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(YeelightServer).SetState(ctx, req.(*SetStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Yeelight_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(YeelightServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Yeelight_StreamEventsServer = grpc.ServerStreamingServer[Event]
+
+// Yeelight_ServiceDesc is the grpc.ServiceDesc for Yeelight service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Yeelight_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "yeelight.Yeelight",
+	HandlerType: (*YeelightServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLights",
+			Handler:    _Yeelight_ListLights_Handler,
+		},
+		{
+			MethodName: "GetState",
+			Handler:    _Yeelight_GetState_Handler,
+		},
+		{
+			MethodName: "SetState",
+			Handler:    _Yeelight_SetState_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Yeelight_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "yeelight.proto",
+}
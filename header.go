@@ -0,0 +1,77 @@
+package yeelight
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// HeaderError is a malformed header of an SSDP message
+type HeaderError struct {
+	Header string
+	Value  string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *HeaderError) Error() string {
+	return fmt.Sprintf("header %s %q: %s", e.Header, e.Value, e.Err)
+}
+
+// Unwrap returns the cause of the error
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// headerInt returns the integer value of header name, missing
+// optional headers are zero
+func headerInt(h http.Header, name string, required bool) (int, error) {
+	v := strings.TrimSpace(h.Get(name))
+	if v == "" {
+		if required {
+			return 0, &HeaderError{Header: name, Err: errMissingHeader}
+		}
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, &HeaderError{Header: name, Value: v, Err: err}
+	}
+	return i, nil
+}
+
+// parseLocation returns the control address of a Location header
+// like yeelight://192.168.1.239:55443, the port defaults to
+// the control port if missing
+func parseLocation(loc string) (string, error) {
+	loc = strings.TrimSpace(loc)
+	fail := func(err error) (string, error) {
+		return "", &HeaderError{Header: "Location", Value: loc, Err: err}
+	}
+	if i := strings.Index(loc, "%"); i > 0 && !strings.HasPrefix(loc[i:], "%25") {
+		// Zones of link-local IPv6 hosts come unescaped
+		loc = loc[:i] + "%25" + loc[i+1:]
+	}
+	u, err := url.Parse(loc)
+	if err != nil {
+		return fail(err)
+	}
+	if u.Scheme != "yeelight" {
+		return fail(errWithoutYeelightPrefix)
+	}
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		return fail(errInvalidParam)
+	}
+	if port == "" {
+		port = controlPort
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fail(fmt.Errorf("%w: port %s", errInvalidParam, port))
+	}
+	// IPv6 hosts come bracketed, yeelight://[fe80::1]:55443
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,83 @@
+package yeelight
+
+import (
+	"context"
+	"time"
+)
+
+// Healthy returns true if light is not offline and talked
+// within maxAge
+func (l *Light) Healthy(maxAge time.Duration) bool {
+	seen := l.lastSeen()
+	if seen == 0 || l.getStatus() == OFFLINE {
+		return false
+	}
+	return l.now().Sub(time.Unix(seen, 0)) <= maxAge
+}
+
+// seen records light talked now
+func (l *Light) seen() {
+	l.mu.Lock()
+	l.LastSeen = l.now().Unix()
+	l.mu.Unlock()
+}
+
+// lastSeen returns LastSeen, written by the receiver and health probes
+func (l *Light) lastSeen() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.LastSeen
+}
+
+// Unhealthy returns the lights not healthy within maxAge sorted by ID
+func (ls *Lights) Unhealthy(maxAge time.Duration) []*Light {
+	var list []*Light
+	for _, l := range ls.list() {
+		if !l.Healthy(maxAge) {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+// ProbeHealth dials the control port of the unhealthy lights every
+// interval until ctx is done. Reachable lights are seen again, the
+// others are marked offline. Lights only accept a few connections
+// so the healthy ones are left alone
+func (ls *Lights) ProbeHealth(ctx context.Context, interval, maxAge time.Duration) {
+	t := ls.clock.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C():
+			for _, l := range ls.Unhealthy(maxAge) {
+				l.probeHealth(ctx)
+			}
+		}
+	}
+}
+
+// probeHealth dials light's control port updating its health
+func (l *Light) probeHealth(ctx context.Context) {
+	d, err := l.cfg.newDialer()
+	if err != nil {
+		return
+	}
+	cn, err := d.DialContext(ctx, "tcp", l.Address)
+	if err != nil {
+		l.log().WithField("error", err).Debugf("Health probe failed")
+		l.setStatus(OFFLINE)
+		return
+	}
+	cn.Close()
+	l.mu.Lock()
+	l.LastSeen = l.now().Unix()
+	// Reachable but nobody is connected
+	unused := l.Status == OFFLINE && l.transport == nil
+	l.mu.Unlock()
+	if unused {
+		l.setStatus(SSDP)
+	}
+}
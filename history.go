@@ -0,0 +1,79 @@
+package yeelight
+
+import "time"
+
+// stateRing keeps the last state changes in a fixed size buffer
+type stateRing struct {
+	buf  []StateChange
+	next int
+	full bool
+}
+
+// push adds sc overwriting the oldest change when full
+func (r *stateRing) push(sc StateChange) {
+	r.buf[r.next] = sc
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// since returns the changes after t, oldest first
+func (r *stateRing) since(t time.Time) []StateChange {
+	var h []StateChange
+	start, n := 0, r.next
+	if r.full {
+		start, n = r.next, len(r.buf)
+	}
+	for i := 0; i < n; i++ {
+		sc := r.buf[(start+i)%len(r.buf)]
+		if sc.At.After(t) {
+			h = append(h, sc)
+		}
+	}
+	return h
+}
+
+// WithStateHistory keeps the last n state changes of each light
+// for History, zero disables it
+func WithStateHistory(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.stateHistory = n
+		}
+	}
+}
+
+// History returns the state changes recorded after since, oldest
+// first, a zero since returns all of them. Changes are only
+// recorded with WithStateHistory
+func (l *Light) History(since time.Time) []StateChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.states == nil {
+		return nil
+	}
+	return l.states.since(since)
+}
+
+// recordState adds sc to light's history if enabled
+func (l *Light) recordState(sc StateChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := l.cfg.stateHistory
+	if n == 0 {
+		l.states = nil
+		return
+	}
+	if l.states == nil || len(l.states.buf) != n {
+		// Created or resized, keeping what fits
+		old := l.states
+		l.states = &stateRing{buf: make([]StateChange, n)}
+		if old != nil {
+			for _, c := range old.since(time.Time{}) {
+				l.states.push(c)
+			}
+		}
+	}
+	l.states.push(sc)
+}
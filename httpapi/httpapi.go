@@ -0,0 +1,149 @@
+// Package httpapi serves a yeelight.Registry over HTTP with JSON
+// bodies, the building block for web UIs and webhook automations.
+//
+//	GET    /lights               list lights, ?tag=bedroom lists the tagged ones
+//	GET    /lights/{id}          light state
+//	PUT    /lights/{id}/power    {"on": true, "duration": 500}
+//	PUT    /lights/{id}/bright   {"bright": 50, "duration": 500}
+//	PUT    /lights/{id}/color    {"rgb": 16711680} or {"hue": 120, "sat": 100} or {"ct": 2700}
+//	PUT    /lights/{id}/scene    {"class": "color", "values": [16711680, 100]}
+//	POST   /lights/{id}/effect   a yeelight.Flow
+//	DELETE /lights/{id}/effect   stops the running flow
+//
+// Lights are addressed by ID, alias or name. Commands answer 204
+// once the light confirms them.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/internal/lightcmd"
+)
+
+const (
+	// Default time a command waits for the light
+	defaultTimeout = 5 * time.Second
+	// Largest request body accepted
+	maxBody = 64 << 10
+)
+
+// Server is an http.Handler controlling the lights of a Registry,
+// they must be listened to get commands' results
+type Server struct {
+	reg *yeelight.Registry
+	mux *http.ServeMux
+	// Timeout bounds how long a command waits for the light
+	Timeout time.Duration
+}
+
+// New returns a Server for the lights in reg
+func New(reg *yeelight.Registry) *Server {
+	s := &Server{reg: reg, mux: http.NewServeMux(), Timeout: defaultTimeout}
+	s.mux.HandleFunc("GET /lights", s.list)
+	s.mux.HandleFunc("GET /lights/{id}", s.get)
+	s.mux.HandleFunc("PUT /lights/{id}/power", s.command(lightcmd.Power))
+	s.mux.HandleFunc("PUT /lights/{id}/bright", s.command(lightcmd.Bright))
+	s.mux.HandleFunc("PUT /lights/{id}/color", s.command(lightcmd.Color))
+	s.mux.HandleFunc("PUT /lights/{id}/scene", s.command(lightcmd.Scene))
+	s.mux.HandleFunc("POST /lights/{id}/effect", s.command(lightcmd.Effect))
+	s.mux.HandleFunc("DELETE /lights/{id}/effect", s.command(lightcmd.StopEffect))
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	found := s.reg.Query()
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		found = s.reg.ByTag(tag)
+	}
+	lights := []yeelight.LightJSON{}
+	for _, l := range found {
+		lights = append(lights, l.JSON())
+	}
+	writeJSON(w, http.StatusOK, lights)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	l := s.light(w, r)
+	if l == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, l.JSON())
+}
+
+// light returns the light in the request path answering 404 if unknown
+func (s *Server) light(w http.ResponseWriter, r *http.Request) *yeelight.Light {
+	id := r.PathValue("id")
+	l := s.reg.Lookup(id)
+	if l == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown light %q", id))
+	}
+	return l
+}
+
+// command returns a handler sending the command built by b
+func (s *Server) command(b lightcmd.Builder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := s.light(w, r)
+		if l == nil {
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		method, params, err := b(body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if !l.Support[method] {
+			writeError(w, http.StatusNotImplemented, fmt.Errorf("light does not support %s", method))
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), s.Timeout)
+		defer cancel()
+		if _, err := l.Call(ctx, method, params...); err != nil {
+			writeError(w, status(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// status maps command errors to HTTP status codes
+func status(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, yeelight.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, yeelight.ErrMethodNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, yeelight.ErrDevice):
+		return http.StatusBadGateway
+	}
+	return http.StatusServiceUnavailable
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,145 @@
+// Package lightcmd builds light commands from the JSON bodies
+// shared by the httpapi and mqttbridge packages
+package lightcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+// Builder turns a JSON body into a light command
+type Builder func(body []byte) (method string, params []interface{}, err error)
+
+// transition returns the effect and duration params of a change lasting ms
+func transition(ms int) ([]interface{}, error) {
+	return yeelight.Transition(time.Duration(ms) * time.Millisecond).Params()
+}
+
+// decode unmarshals body into v, an empty body is an error
+func decode(body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return errors.New("missing JSON body")
+	}
+	return json.Unmarshal(body, v)
+}
+
+// Power builds set_power from {"on": true, "duration": 500}
+func Power(body []byte) (string, []interface{}, error) {
+	var req struct {
+		On       *bool `json:"on"`
+		Duration int   `json:"duration"`
+	}
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	if req.On == nil {
+		return "", nil, errors.New(`"on" is required`)
+	}
+	p := "off"
+	if *req.On {
+		p = "on"
+	}
+	t, err := transition(req.Duration)
+	if err != nil {
+		return "", nil, err
+	}
+	return "set_power", append([]interface{}{p}, t...), nil
+}
+
+// Bright builds set_bright from {"bright": 50, "duration": 500}
+func Bright(body []byte) (string, []interface{}, error) {
+	var req struct {
+		Bright   int `json:"bright"`
+		Duration int `json:"duration"`
+	}
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	if req.Bright < 1 || req.Bright > 100 {
+		return "", nil, fmt.Errorf("brightness %d out of 1-100", req.Bright)
+	}
+	t, err := transition(req.Duration)
+	if err != nil {
+		return "", nil, err
+	}
+	return "set_bright", append([]interface{}{req.Bright}, t...), nil
+}
+
+// Color builds set_rgb, set_hsv or set_ct_abx from {"rgb": 16711680},
+// {"hue": 120, "sat": 100} or {"ct": 2700}, with optional duration
+func Color(body []byte) (string, []interface{}, error) {
+	var req struct {
+		RGB      *int `json:"rgb"`
+		Hue      *int `json:"hue"`
+		Sat      *int `json:"sat"`
+		CT       *int `json:"ct"`
+		Duration int  `json:"duration"`
+	}
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	t, err := transition(req.Duration)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case req.RGB != nil:
+		if *req.RGB < 0 || *req.RGB > 0xFFFFFF {
+			return "", nil, fmt.Errorf("rgb %d out of range", *req.RGB)
+		}
+		return "set_rgb", append([]interface{}{*req.RGB}, t...), nil
+	case req.Hue != nil && req.Sat != nil:
+		if *req.Hue < 0 || *req.Hue > 359 || *req.Sat < 0 || *req.Sat > 100 {
+			return "", nil, fmt.Errorf("hue %d or sat %d out of range", *req.Hue, *req.Sat)
+		}
+		return "set_hsv", append([]interface{}{*req.Hue, *req.Sat}, t...), nil
+	case req.CT != nil:
+		if *req.CT < 1700 || *req.CT > 6500 {
+			return "", nil, fmt.Errorf("ct %d out of 1700-6500", *req.CT)
+		}
+		return "set_ct_abx", append([]interface{}{*req.CT}, t...), nil
+	}
+	return "", nil, errors.New(`one of "rgb", "hue" and "sat" or "ct" is required`)
+}
+
+// Scene builds set_scene from {"class": "color", "values": [16711680, 100]}
+func Scene(body []byte) (string, []interface{}, error) {
+	var req struct {
+		Class  string        `json:"class"`
+		Values []interface{} `json:"values"`
+	}
+	if err := decode(body, &req); err != nil {
+		return "", nil, err
+	}
+	switch req.Class {
+	case "color", "hsv", "ct", "cf", "auto_delay_off":
+	default:
+		return "", nil, fmt.Errorf("unknown scene class %q", req.Class)
+	}
+	return "set_scene", append([]interface{}{req.Class}, req.Values...), nil
+}
+
+// Effect builds start_cf from a yeelight.Flow
+func Effect(body []byte) (string, []interface{}, error) {
+	var f yeelight.Flow
+	if err := decode(body, &f); err != nil {
+		return "", nil, err
+	}
+	expr, err := f.Expression()
+	if err != nil {
+		return "", nil, err
+	}
+	if f.Action < yeelight.FlowRecover || f.Action > yeelight.FlowOff {
+		return "", nil, fmt.Errorf("flow action %d out of range", f.Action)
+	}
+	return "start_cf", []interface{}{f.Count, int(f.Action), expr}, nil
+}
+
+// StopEffect builds stop_cf, it takes no body
+func StopEffect([]byte) (string, []interface{}, error) {
+	return "stop_cf", []interface{}{""}, nil
+}
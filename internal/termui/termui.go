@@ -0,0 +1,103 @@
+// Package termui writes per-light status lines and progress bars
+// for the command line tools, colors are disabled when NO_COLOR is
+// set or the output is not a terminal
+package termui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	gray  = "\x1b[90m"
+	reset = "\x1b[0m"
+)
+
+// UI writes status lines and progress to a terminal
+type UI struct {
+	mu       sync.Mutex
+	w        io.Writer
+	color    bool
+	tty      bool
+	total    int
+	done     int
+	label    string
+	progress bool
+}
+
+// New returns a UI writing to w
+func New(w io.Writer) *UI {
+	tty := isTerminal(w)
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return &UI{w: w, tty: tty, color: tty && !noColor}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	st, err := f.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
+
+func (u *UI) paint(color, s string) string {
+	if !u.color {
+		return s
+	}
+	return color + s + reset
+}
+
+// Start begins a progress bar for total operations labeled label
+func (u *UI) Start(label string, total int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.label, u.total, u.done, u.progress = label, total, 0, true
+	u.drawProgress()
+}
+
+// Status prints the result of an operation on light name and
+// advances the progress bar, err nil means success
+func (u *UI) Status(name string, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.clearProgress()
+	if err == nil {
+		fmt.Fprintf(u.w, "%s %s\n", u.paint(green, "ok  "), name)
+	} else {
+		fmt.Fprintf(u.w, "%s %s %s\n", u.paint(red, "fail"), name, u.paint(gray, err.Error()))
+	}
+	if u.progress {
+		u.done++
+		u.drawProgress()
+	}
+}
+
+// Finish ends the progress bar
+func (u *UI) Finish() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.clearProgress()
+	u.progress = false
+}
+
+func (u *UI) drawProgress() {
+	if !u.tty || u.total <= 0 {
+		return
+	}
+	const width = 30
+	n := u.done * width / u.total
+	fmt.Fprintf(u.w, "\r%s [%s%s] %d/%d", u.label,
+		strings.Repeat("=", n), strings.Repeat(" ", width-n), u.done, u.total)
+}
+
+func (u *UI) clearProgress() {
+	if u.tty && u.progress {
+		fmt.Fprint(u.w, "\r\x1b[K")
+	}
+}
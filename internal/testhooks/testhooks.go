@@ -0,0 +1,27 @@
+// Package testhooks lets yeelighttest reach the internals of lights
+// without exporting them from the yeelight package, which sets the
+// hooks when initialized
+package testhooks
+
+// Command is a command held by a light
+type Command struct {
+	ID     int32
+	Method string
+	Params []interface{}
+}
+
+// Snapshot is a copy of a light's internal queues
+type Snapshot struct {
+	PendingCalls   []Command
+	QueuedCommands []Command
+	Backlogs       map[string]int
+	Dropped        uint64
+	Frozen         bool
+}
+
+var (
+	// Internals returns the Snapshot of a *yeelight.Light
+	Internals func(light interface{}) Snapshot
+	// SetFrozen freezes or thaws a *yeelight.Light
+	SetFrozen func(light interface{}, frozen bool)
+)
@@ -0,0 +1,93 @@
+package yeelight
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pulento/yeelight/internal/testhooks"
+)
+
+// subscription is a channel handed out to a consumer
+type subscription struct {
+	name    string
+	pending func() int
+	mu      sync.Mutex
+	done    bool
+}
+
+func init() {
+	testhooks.Internals = func(v interface{}) testhooks.Snapshot { return v.(*Light).internals() }
+	testhooks.SetFrozen = func(v interface{}, frozen bool) { v.(*Light).setFrozen(frozen) }
+}
+
+// internals returns a snapshot of light's internal queues
+func (l *Light) internals() testhooks.Snapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	in := testhooks.Snapshot{
+		PendingCalls:   make([]testhooks.Command, 0, len(l.Calls)),
+		QueuedCommands: make([]testhooks.Command, 0, len(l.queue)),
+		Backlogs:       make(map[string]int, len(l.subs)),
+		Dropped:        l.dropped.Load(),
+		Frozen:         l.frozen,
+	}
+	for _, c := range l.Calls {
+		in.PendingCalls = append(in.PendingCalls, testhooks.Command(*c))
+	}
+	sort.Slice(in.PendingCalls, func(i, j int) bool {
+		return in.PendingCalls[i].ID < in.PendingCalls[j].ID
+	})
+	for _, q := range l.queue {
+		in.QueuedCommands = append(in.QueuedCommands, testhooks.Command(*q.cmd))
+	}
+	for _, s := range l.subs {
+		in.Backlogs[s.name] += s.pending()
+	}
+	return in
+}
+
+// setFrozen stops or resumes expiring pending calls and flushing
+// queued commands, thawing flushes them if the light is connected
+func (l *Light) setFrozen(frozen bool) {
+	l.mu.Lock()
+	l.frozen = frozen
+	l.mu.Unlock()
+	if !frozen && l.transport != nil && l.getStatus() != OFFLINE {
+		go l.flushQueue()
+	}
+}
+
+// subscribe records a channel for backlog inspection until ctx is
+// done, then the entry is removed and end closes the channel
+func (l *Light) subscribe(ctx context.Context, name string, pending func() int, end func()) *subscription {
+	s := &subscription{name: name, pending: pending}
+	l.mu.Lock()
+	l.subs = append(l.subs, s)
+	l.mu.Unlock()
+	context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		for i, o := range l.subs {
+			if o == s {
+				l.subs = append(l.subs[:i], l.subs[i+1:]...)
+				break
+			}
+		}
+		l.mu.Unlock()
+		s.mu.Lock()
+		s.done = true
+		end()
+		s.mu.Unlock()
+	})
+	return s
+}
+
+// send runs deliver unless the subscription was cancelled,
+// so nothing is sent on a closed channel
+func (s *subscription) send(deliver func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.done {
+		deliver()
+	}
+}
@@ -0,0 +1,122 @@
+package yeelight
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Link-local SSDP group lights listen to on IPv6 networks
+var mcastAddressV6 = "[ff02::c]:1982"
+
+var ipv6Discovery bool
+
+// EnableIPv6Discovery makes searches and monitors also use IPv6
+// multicast in addition to IPv4
+func EnableIPv6Discovery(on bool) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	ipv6Discovery = on
+}
+
+func ipv6Enabled() bool {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return ipv6Discovery
+}
+
+// udpNetwork returns the UDP network matching addr's family
+func udpNetwork(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err == nil && strings.Contains(host, ":") {
+		return "udp6"
+	}
+	return "udp4"
+}
+
+// searchV6 runs an IPv6 M-SEARCH for wait seconds
+func searchV6(wait int, localAddr string) []http.Header {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
+	defer cancel()
+	headers, err := goSSDP{}.SearchStream(ctx, mcastAddressV6, searchType, localAddr)
+	if err != nil {
+		log().Errorf("Error searching on IPv6: %s", err)
+		return nil
+	}
+	var list []http.Header
+	for h := range headers {
+		list = append(list, h)
+	}
+	return list
+}
+
+// mergeHeaders forwards all headers from chs to a single channel
+// until they are closed or ctx is done
+func mergeHeaders(ctx context.Context, chs ...<-chan http.Header) <-chan http.Header {
+	out := make(chan http.Header)
+	var wg sync.WaitGroup
+	for _, c := range chs {
+		wg.Add(1)
+		go func(c <-chan http.Header) {
+			defer wg.Done()
+			for h := range c {
+				select {
+				case out <- h:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(c)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// monitorV6 listens IPv6 SSDP announcements calling alive with their headers
+func monitorV6(alive func(header http.Header)) (io.Closer, error) {
+	gaddr, err := net.ResolveUDPAddr("udp6", mcastAddressV6)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp6", nil, gaddr)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(buf[:n])))
+			if err != nil || req.Method != "NOTIFY" {
+				continue
+			}
+			alive(req.Header)
+		}
+	}()
+	return conn, nil
+}
+
+// closers closes several monitors at once
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var first error
+	for _, c := range cs {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
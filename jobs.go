@@ -0,0 +1,180 @@
+package yeelight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// JobSpec is a recurring command for lights, kept by the Scheduler
+// and persisted with Persist
+type JobSpec struct {
+	Name string `json:"name"`
+	// Cron is a cron expression as understood by ParseCron,
+	// like "30 7 * * 1-5" or "@every 2h"
+	Cron string `json:"cron"`
+	// Lights are IDs or names of the lights commanded, along
+	// with the lights of Group
+	Lights []string  `json:"lights,omitempty"`
+	Group  string    `json:"group,omitempty"`
+	Action JobAction `json:"action"`
+}
+
+// JobAction is the command of a job, only one field is set
+type JobAction struct {
+	// Power is "on", "off" or "toggle"
+	Power string `json:"power,omitempty"`
+	// Scene are the set_scene params, like ["ct", 2700, 40]
+	Scene []interface{} `json:"scene,omitempty"`
+	Flow  *Flow         `json:"flow,omitempty"`
+}
+
+// command returns the light command of a
+func (a JobAction) command() (string, []interface{}, error) {
+	switch {
+	case a.Power == "toggle":
+		return "toggle", []interface{}{""}, nil
+	case a.Power == "on" || a.Power == "off":
+		return "set_power", []interface{}{a.Power, "sudden", 0}, nil
+	case a.Power != "":
+		return "", nil, fmt.Errorf("%w: power %q", errInvalidParam, a.Power)
+	case len(a.Scene) > 0:
+		return "set_scene", a.Scene, nil
+	case a.Flow != nil:
+		expr, err := a.Flow.Expression()
+		if err != nil {
+			return "", nil, err
+		}
+		return "start_cf", []interface{}{a.Flow.Count, int(a.Flow.Action), expr}, nil
+	}
+	return "", nil, fmt.Errorf("%w: job without action", errInvalidParam)
+}
+
+// AddGroup makes g available to jobs by its name
+func (s *Scheduler) AddGroup(g *Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[g.Name] = g
+}
+
+// Schedule adds job spec replacing the one with the same name
+func (s *Scheduler) Schedule(spec JobSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("%w: job without name", errInvalidParam)
+	}
+	sched, err := ParseCron(spec.Cron)
+	if err != nil {
+		return err
+	}
+	if _, _, err := spec.Action.command(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.specs[spec.Name] = spec
+	err = s.save()
+	s.mu.Unlock()
+	s.Add(spec.Name, sched, func() { s.runJob(spec) })
+	return err
+}
+
+// Specs returns the scheduled job specs sorted by name
+func (s *Scheduler) Specs() []JobSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	specs := make([]JobSpec, 0, len(s.specs))
+	for _, name := range sortedKeys(s.specs) {
+		specs = append(specs, s.specs[name])
+	}
+	return specs
+}
+
+// runJob sends the command of spec to its lights
+func (s *Scheduler) runJob(spec JobSpec) {
+	method, params, _ := spec.Action.command()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if _, err := Broadcast(ctx, s.targets(spec), 0, method, params...); err != nil {
+		s.log().WithFields(Fields{"job": spec.Name, "error": err}).Errorf("Scheduled job failed")
+	}
+}
+
+// log returns Scheduler's logger or the package one
+func (s *Scheduler) log() Logger {
+	if s.logger == nil {
+		return log()
+	}
+	return s.logger
+}
+
+// targets returns the lights commanded by spec
+func (s *Scheduler) targets(spec JobSpec) []*Light {
+	var lights []*Light
+	seen := make(map[*Light]bool)
+	add := func(l *Light) {
+		if !seen[l] {
+			seen[l] = true
+			lights = append(lights, l)
+		}
+	}
+	if s.lights != nil {
+		for _, l := range s.lights.list() {
+			for _, ref := range spec.Lights {
+				if matchLight(l, ref) {
+					add(l)
+				}
+			}
+		}
+	}
+	s.mu.Lock()
+	g := s.groups[spec.Group]
+	s.mu.Unlock()
+	if g != nil {
+		for _, l := range g.Lights {
+			add(l)
+		}
+	}
+	return lights
+}
+
+// Persist schedules the jobs saved in path, if it exists, and
+// saves the job specs there on every change from then on
+func (s *Scheduler) Persist(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var specs []JobSpec
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &specs); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.path = path
+	s.mu.Unlock()
+	for _, spec := range specs {
+		if err := s.Schedule(spec); err != nil {
+			return fmt.Errorf("job %q: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// save writes the specs to the persistence file if any,
+// atomically. s.mu must be held
+func (s *Scheduler) save() error {
+	if s.path == "" {
+		return nil
+	}
+	specs := make([]JobSpec, 0, len(s.specs))
+	for _, name := range sortedKeys(s.specs) {
+		specs = append(specs, s.specs[name])
+	}
+	data, err := json.MarshalIndent(specs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
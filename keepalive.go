@@ -0,0 +1,50 @@
+package yeelight
+
+import "time"
+
+// WithKeepAlive sets the TCP keepalive period of lights' connections,
+// a negative period disables it
+func WithKeepAlive(period time.Duration) Option {
+	return func(c *config) {
+		c.keepAlive = period
+	}
+}
+
+// WithLivenessCheck pings a light silent for period and reconnects it
+// if no reply arrives before timeout, a zero period disables it.
+// Some routers drop idle connections without the light noticing
+func WithLivenessCheck(period, timeout time.Duration) Option {
+	return func(c *config) {
+		c.livenessPeriod = period
+		if timeout > 0 {
+			c.livenessTimeout = timeout
+		}
+	}
+}
+
+// idle returns how long ago light sent something
+func (l *Light) idle() time.Duration {
+	return l.now().Sub(time.Unix(l.lastSeen(), 0))
+}
+
+// checkAlive pings light closing connection gen if it doesn't reply
+// in time, the read error that follows makes the listener reconnect.
+// Pings are queries so they are answered in music mode too
+func (l *Light) checkAlive(gen uint64) {
+	if l.transport == nil {
+		return
+	}
+	id, err := l.Ping()
+	if err != nil {
+		// Failed sends reconnect on their own
+		return
+	}
+	r := l.waitResult(id, l.cfg.livenessTimeout)
+	if r != nil && (r.Error == nil || r.Error.Code != errCodeTimeout) {
+		return
+	}
+	if l.connGen.Load() == gen {
+		l.log().Warnf("Light not responding, dropping connection")
+		l.transport.Close()
+	}
+}
@@ -0,0 +1,87 @@
+package yeelight
+
+import (
+	"sort"
+	"time"
+)
+
+// Number of round trips kept per light for latency statistics
+const latencySamples = 100
+
+// latencies keeps the last round-trip times of a light's commands
+type latencies struct {
+	samples [latencySamples]time.Duration
+	n       int
+}
+
+func (lt *latencies) add(d time.Duration) {
+	lt.samples[lt.n%latencySamples] = d
+	lt.n++
+}
+
+// Latency is the round-trip time, from sending a command to getting
+// its result, of the last commands of a light. Commands timing out
+// and commands sent in music mode are not counted
+type Latency struct {
+	Samples int           `json:"samples"`
+	Last    time.Duration `json:"last"`
+	Avg     time.Duration `json:"avg"`
+	P50     time.Duration `json:"p50"`
+	P90     time.Duration `json:"p90"`
+	P99     time.Duration `json:"p99"`
+	Max     time.Duration `json:"max"`
+}
+
+// Latency returns the round-trip statistics of light's last commands,
+// all zero if no command got its result yet
+func (l *Light) Latency() Latency {
+	l.mu.Lock()
+	n := l.rtt.n
+	if n > latencySamples {
+		n = latencySamples
+	}
+	sorted := append([]time.Duration(nil), l.rtt.samples[:n]...)
+	var last time.Duration
+	if n > 0 {
+		last = l.rtt.samples[(l.rtt.n-1)%latencySamples]
+	}
+	l.mu.Unlock()
+	if n == 0 {
+		return Latency{}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	pct := func(p int) time.Duration {
+		return sorted[(n-1)*p/100]
+	}
+	return Latency{
+		Samples: n,
+		Last:    last,
+		Avg:     sum / time.Duration(n),
+		P50:     pct(50),
+		P90:     pct(90),
+		P99:     pct(99),
+		Max:     sorted[n-1],
+	}
+}
+
+// Responsive matches lights whose 90th percentile latency is
+// within max, lights without results yet don't match
+func Responsive(max time.Duration) Filter {
+	return func(l *Light) bool {
+		lt := l.Latency()
+		return lt.Samples > 0 && lt.P90 <= max
+	}
+}
+
+// Latencies returns the latency statistics of Manager's lights by ID
+func (m *Manager) Latencies() map[string]Latency {
+	lats := make(map[string]Latency)
+	for l := range m.All() {
+		lats[l.ID] = l.Latency()
+	}
+	return lats
+}
@@ -0,0 +1,273 @@
+package yeelight_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/yeelighttest"
+)
+
+// listen returns a light listening bulb, both closed at the end of t
+func listen(t *testing.T, b *yeelighttest.Bulb, opts ...yeelight.Option) *yeelight.Light {
+	t.Helper()
+	t.Cleanup(b.Close)
+	l, err := b.Light()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Listen(make(chan *yeelight.ResultNotification, 64), opts...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Shutdown(context.Background()) })
+	return l
+}
+
+// events returns a channel with the events of kind sent to a
+// handler option, lights drop events when it is full
+func events(kind yeelight.EventKind) (yeelight.Option, <-chan yeelight.Event) {
+	c := make(chan yeelight.Event, 64)
+	return yeelight.WithEventHandler(yeelight.EventHandlerFunc(func(e yeelight.Event) {
+		if e.Kind == kind {
+			select {
+			case c <- e:
+			default:
+			}
+		}
+	})), c
+}
+
+func TestSendCommand(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	l := listen(t, b)
+
+	id, err := l.SendCommand("set_bright", 30, "sudden", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := l.WaitResultCtx(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Err() != nil || len(r.Result) != 1 || r.Result[0] != "ok" {
+		t.Errorf("result = %v, %v, want [ok]", r.Result, r.Err())
+	}
+	if got := b.Prop("bright"); got != "30" {
+		t.Errorf("bulb bright = %s, want 30", got)
+	}
+}
+
+func TestSendCommandError(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	l := listen(t, b)
+
+	b.FailNext("set_power", -1, "invalid command")
+	_, err := l.Call(context.Background(), "set_power", "off", "sudden", 0)
+	if !errors.Is(err, yeelight.ErrInvalidCommand) {
+		t.Errorf("error = %v, want ErrInvalidCommand", err)
+	}
+	if got := b.Prop("power"); got != "on" {
+		t.Errorf("bulb power = %s, want on", got)
+	}
+}
+
+func TestWaitResultTimeout(t *testing.T) {
+	// A light accepting commands but never answering them
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		io.Copy(io.Discard, c)
+		c.Close()
+	}()
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	h := b.Header()
+	h.Set("Location", "yeelight://"+ln.Addr().String())
+	l, err := yeelight.Parse(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Listen(make(chan *yeelight.ResultNotification, 1)); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Shutdown(context.Background())
+
+	id, err := l.SendCommand("set_power", "off", "sudden", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = l.WaitResultCtx(ctx, id)
+	if !errors.Is(err, yeelight.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want ErrTimeout and DeadlineExceeded", err)
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	b := yeelighttest.NewUnstartedBulb()
+	b.Quota = 2
+	b.Start()
+	l := listen(t, b)
+
+	ctx := context.Background()
+	for i := 0; i < b.Quota; i++ {
+		if _, err := l.Call(ctx, "set_bright", 10+i, "sudden", 0); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+	}
+	_, err := l.Call(ctx, "set_bright", 50, "sudden", 0)
+	if !errors.Is(err, yeelight.ErrQuotaExceeded) {
+		t.Errorf("error = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestReconnectResync(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	resynced, resyncs := events(yeelight.EventResync)
+	l := listen(t, b, resynced,
+		yeelight.WithReconnectPolicy(yeelight.ReconnectPolicy{InitialDelay: 10 * time.Millisecond}))
+
+	// The state changes while the light is unreachable
+	b.Drop(map[string]string{"bright": "42"})
+	select {
+	case <-resyncs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("light did not resync after reconnecting")
+	}
+	if l.Bright != 42 {
+		t.Errorf("bright = %d after resync, want 42", l.Bright)
+	}
+	if n := b.Conns(); n != 1 {
+		t.Errorf("bulb has %d connections, want 1", n)
+	}
+}
+
+func TestAutoMusic(t *testing.T) {
+	b := yeelighttest.NewUnstartedBulb()
+	b.Quota = 5
+	b.Start()
+	l := listen(t, b, yeelight.WithAutoMusic(3), yeelight.WithConnectTimeout(time.Second))
+
+	// Twice the quota goes through, music mode has none
+	for i := 1; i <= 2*b.Quota; i++ {
+		if _, err := l.SetBrightness(i, yeelight.Sudden); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+	}
+	want := strconv.Itoa(2 * b.Quota)
+	deadline := time.Now().Add(time.Second)
+	for b.Prop("bright") != want && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := b.Prop("bright"); got != want {
+		t.Errorf("bulb bright = %s, want %s", got, want)
+	}
+	if b.Prop("music_on") != "1" {
+		t.Error("light not switched to music mode")
+	}
+}
+
+func TestAutoMusicBackoff(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	sent, commands := events(yeelight.EventCommandSent)
+	l := listen(t, b, sent, yeelight.WithAutoMusic(3), yeelight.WithConnectTimeout(100*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		b.FailNext("set_music", -1, "general error")
+		id, err := l.SetBrightness(10+i, yeelight.Sudden)
+		if err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+		l.WaitResultCtx(context.Background(), id)
+	}
+	music := 0
+	for len(commands) > 0 {
+		if e := <-commands; e.Command.Method == "set_music" {
+			music++
+		}
+	}
+	if music != 1 {
+		t.Errorf("set_music sent %d times, want once until the backoff ends", music)
+	}
+	if b.Prop("music_on") == "1" {
+		t.Error("light in music mode after set_music failed")
+	}
+}
+
+func TestCancelSubscription(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	l := listen(t, b)
+	ctx, cancel := context.WithCancel(context.Background())
+	c := l.StateChanges(ctx, 4)
+
+	b.SetProp("bright", "40")
+	select {
+	case sc := <-c:
+		if sc.Changed["bright"].New != "40" {
+			t.Errorf("changed %v, want bright 40", sc.Changed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no state change")
+	}
+	if _, ok := yeelighttest.Inspect(l).Backlogs["state"]; !ok {
+		t.Error("subscription missing from backlogs")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-c:
+		if ok {
+			t.Fatal("state change after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed on cancel")
+	}
+	if _, ok := yeelighttest.Inspect(l).Backlogs["state"]; ok {
+		t.Error("cancelled subscription still in backlogs")
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	s, ms := time.Second, time.Millisecond
+	tests := []struct {
+		name   string
+		policy yeelight.ReconnectPolicy
+		want   []time.Duration
+	}{
+		{"uncapped", yeelight.ReconnectPolicy{InitialDelay: s}, []time.Duration{0, s, 2 * s, 4 * s, 8 * s}},
+		{"capped", yeelight.ReconnectPolicy{InitialDelay: s, MaxDelay: 3 * s}, []time.Duration{0, s, 2 * s, 3 * s, 3 * s}},
+		{"zero floored", yeelight.ReconnectPolicy{}, []time.Duration{0, 100 * ms, 200 * ms, 400 * ms}},
+	}
+	for _, tt := range tests {
+		for attempt, want := range tt.want {
+			if got := yeelight.ReconnectDelay(tt.policy, attempt); got != want {
+				t.Errorf("%s: attempt %d waits %s, want %s", tt.name, attempt, got, want)
+			}
+		}
+	}
+	// Doubling forever neither overflows nor leaves the jitter range
+	p := yeelight.ReconnectPolicy{InitialDelay: s, Jitter: 0.5}
+	for _, attempt := range []int{1, 10, 100, 1000} {
+		if d := yeelight.ReconnectDelay(p, attempt); d <= 0 {
+			t.Errorf("attempt %d waits %s", attempt, d)
+		}
+	}
+	if d := yeelight.ReconnectDelay(p, 3); d < 2*s || d > 6*s {
+		t.Errorf("attempt 3 waits %s, want 4s ± 50%%", d)
+	}
+}
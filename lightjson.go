@@ -0,0 +1,118 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// LightSchemaVersion is the version of the LightJSON schema,
+// it changes only when fields are removed or change meaning
+const LightSchemaVersion = 1
+
+// LightJSON is how a Light is serialized, decode into it to read
+// lights back. Zero values are omitted for properties the model lacks
+type LightJSON struct {
+	Version int    `json:"version"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Model   string `json:"model"`
+	FW      int    `json:"fw"`
+	// Status is the connectivity: offline, ssdp, updating or online
+	Status Status `json:"status"`
+	// LastSeen is when the light last talked, RFC3339
+	LastSeen string `json:"last_seen,omitempty"`
+	// ExpiresAt is when the SSDP announcement goes stale, RFC3339
+	ExpiresAt string `json:"expires_at,omitempty"`
+	// Power is on, off or unknown
+	Power     PowerState `json:"power"`
+	Bright    int        `json:"bright"`
+	ColorMode int        `json:"color_mode"`
+	CT        int        `json:"ct,omitempty"`
+	RGB       int        `json:"rgb,omitempty"`
+	Hue       int        `json:"hue,omitempty"`
+	Sat       int        `json:"sat,omitempty"`
+	Flowing   bool       `json:"flowing"`
+	// Flow is the running color flow when the light reports it
+	Flow *Flow `json:"flow,omitempty"`
+	// DelayOff is the minutes left before the light turns off
+	DelayOff int  `json:"delayoff,omitempty"`
+	MusicOn  bool `json:"music_on"`
+	// NightBright is the brightness of the moonlight mode
+	NightBright int `json:"nl_br,omitempty"`
+	// Background is set for lights with a background light
+	Background *BackgroundJSON `json:"background,omitempty"`
+	// Capabilities are the methods supported, sorted
+	Capabilities []string `json:"capabilities"`
+}
+
+// BackgroundJSON is the serialized background light of a Light
+type BackgroundJSON struct {
+	Power     PowerState `json:"power"`
+	Bright    int        `json:"bright"`
+	ColorMode int        `json:"color_mode"`
+	CT        int        `json:"ct,omitempty"`
+	RGB       int        `json:"rgb,omitempty"`
+	Hue       int        `json:"hue,omitempty"`
+	Sat       int        `json:"sat,omitempty"`
+	Flowing   bool       `json:"flowing"`
+	Flow      *Flow      `json:"flow,omitempty"`
+}
+
+// JSON returns light in the serialized schema
+func (l *Light) JSON() LightJSON {
+	j := LightJSON{
+		Version:     LightSchemaVersion,
+		ID:          l.ID,
+		Name:        l.Name,
+		Address:     l.Address,
+		Model:       l.Model,
+		FW:          l.FW,
+		Status:      l.getStatus(),
+		Power:       l.PowerState(),
+		Bright:      l.Bright,
+		ColorMode:   l.ColorMode,
+		CT:          l.CT,
+		RGB:         l.RGB,
+		Hue:         l.Hue,
+		Sat:         l.Sat,
+		Flowing:     l.Flowing,
+		Flow:        l.CurrentFlow,
+		DelayOff:    l.DelayOff,
+		MusicOn:     l.MusicOn == 1,
+		NightBright: l.NlBr,
+	}
+	if seen := l.lastSeen(); seen != 0 {
+		j.LastSeen = time.Unix(seen, 0).UTC().Format(time.RFC3339)
+	}
+	if !l.ExpiresAt.IsZero() {
+		j.ExpiresAt = l.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if l.BgPower != "" {
+		j.Background = &BackgroundJSON{
+			Power:     ParsePowerState(l.BgPower),
+			Bright:    l.BgBright,
+			ColorMode: l.BgLMode,
+			CT:        l.BgCT,
+			RGB:       l.BgRGB,
+			Hue:       l.BgHue,
+			Sat:       l.BgSat,
+			Flowing:   l.BgFlowing == 1,
+			Flow:      runningFlow(l.BgFlowing, l.BgFlowParams),
+		}
+	}
+	j.Capabilities = make([]string, 0, len(l.Support))
+	for m, ok := range l.Support {
+		if ok {
+			j.Capabilities = append(j.Capabilities, m)
+		}
+	}
+	sort.Strings(j.Capabilities)
+	return j
+}
+
+// MarshalJSON encodes light with the LightJSON schema
+func (l *Light) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.JSON())
+}
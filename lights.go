@@ -0,0 +1,127 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Lights is a collection of devices indexed by ID safe for
+// concurrent use by the SSDP monitor and applications. Methods
+// taking or returning *Light only see the devices that are lights
+type Lights struct {
+	mu sync.RWMutex
+	m  map[string]Device
+	// Local aliases and tags by light ID, see SetAlias and Tag
+	aliases map[string]string
+	tags    map[string]map[string]bool
+	// Status of the stale lights before going offline
+	stale map[string]Status
+	// Clock of WatchStale and ProbeHealth, the Manager's one
+	clock Clock
+}
+
+// NewLights returns an empty collection
+func NewLights() *Lights {
+	return &Lights{
+		m:       make(map[string]Device),
+		aliases: make(map[string]string),
+		tags:    make(map[string]map[string]bool),
+		stale:   make(map[string]Status),
+		clock:   systemClock{},
+	}
+}
+
+// Get returns the light with id or nil if not found
+func (ls *Lights) Get(id string) *Light {
+	l, _ := ls.Device(id).(*Light)
+	return l
+}
+
+// Device returns the device with id or nil if not found
+func (ls *Lights) Device(id string) Device {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return ls.m[id]
+}
+
+// Put adds or replaces a light
+func (ls *Lights) Put(l *Light) {
+	ls.PutDevice(l)
+}
+
+// PutDevice adds or replaces a device
+func (ls *Lights) PutDevice(d Device) {
+	id := d.Info().ID
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.m[id] = d
+}
+
+// LoadOrStore returns the light stored with l's ID if any,
+// otherwise it stores l. added is true if l was stored, actual
+// is nil if the ID belongs to a device which is not a light
+func (ls *Lights) LoadOrStore(l *Light) (actual *Light, added bool) {
+	d, added := ls.LoadOrStoreDevice(l)
+	actual, _ = d.(*Light)
+	return actual, added
+}
+
+// LoadOrStoreDevice returns the device stored with d's ID if any,
+// otherwise it stores d. added is true if d was stored
+func (ls *Lights) LoadOrStoreDevice(d Device) (actual Device, added bool) {
+	id := d.Info().ID
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if cur, ok := ls.m[id]; ok {
+		return cur, false
+	}
+	ls.m[id] = d
+	return d, true
+}
+
+// Delete removes the device with id
+func (ls *Lights) Delete(id string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	delete(ls.m, id)
+	delete(ls.stale, id)
+}
+
+// Len returns the number of devices
+func (ls *Lights) Len() int {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return len(ls.m)
+}
+
+// Range calls f for each light until f returns false. It works on
+// a snapshot so f may modify the collection
+func (ls *Lights) Range(f func(id string, l *Light) bool) {
+	ls.RangeDevices(func(id string, d Device) bool {
+		l, ok := d.(*Light)
+		return !ok || f(id, l)
+	})
+}
+
+// RangeDevices calls f for each device until f returns false,
+// like Range
+func (ls *Lights) RangeDevices(f func(id string, d Device) bool) {
+	ls.mu.RLock()
+	snap := make(map[string]Device, len(ls.m))
+	for k, v := range ls.m {
+		snap[k] = v
+	}
+	ls.mu.RUnlock()
+	for k, v := range snap {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// MarshalJSON encodes the collection as an object indexed by ID
+func (ls *Lights) MarshalJSON() ([]byte, error) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+	return json.Marshal(ls.m)
+}
@@ -0,0 +1,143 @@
+package yeelight
+
+import "sync"
+
+// OverflowPolicy is what a listener does when its consumer
+// falls behind and the notification queue is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// OverflowBlock stops reading from the light until there is room
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued message
+	OverflowDropOldest
+	// OverflowDropNewest discards the message just received
+	OverflowDropNewest
+)
+
+// WithNotifyQueue sets how many results and notifications are queued
+// for a slow consumer and what happens when the queue is full
+func WithNotifyQueue(size int, policy OverflowPolicy) Option {
+	return func(c *config) {
+		if size >= 0 {
+			c.notifyQueue = size
+		}
+		c.overflow = policy
+	}
+}
+
+// Listener is a running Listen on a light
+type Listener struct {
+	stop   chan struct{}
+	ended  chan struct{}
+	done   chan struct{}
+	once   sync.Once
+	fwd    sync.WaitGroup
+	mu     sync.Mutex
+	err    error
+	queue  chan *ResultNotification
+	policy OverflowPolicy
+}
+
+func newListener(size int, policy OverflowPolicy) *Listener {
+	return &Listener{
+		stop:   make(chan struct{}),
+		ended:  make(chan struct{}),
+		done:   make(chan struct{}),
+		queue:  make(chan *ResultNotification, size),
+		policy: policy,
+	}
+}
+
+// Stop stops listening and closes light's connection,
+// it is safe to call it more than once
+func (ln *Listener) Stop() {
+	ln.once.Do(func() { close(ln.stop) })
+}
+
+// Done returns a channel closed once listening has ended
+func (ln *Listener) Done() <-chan struct{} {
+	return ln.done
+}
+
+// Err returns why listening ended, nil if it was stopped
+// or it is still running
+func (ln *Listener) Err() error {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+	return ln.err
+}
+
+// finish records err and signals listening has ended once forward
+// returned, so nothing is sent to its channel after Done
+func (ln *Listener) finish(err error) {
+	ln.mu.Lock()
+	ln.err = err
+	ln.mu.Unlock()
+	close(ln.ended)
+	ln.fwd.Wait()
+	close(ln.done)
+}
+
+// forward delivers the queued messages to out until listening ends
+func (ln *Listener) forward(out chan<- *ResultNotification) {
+	defer ln.fwd.Done()
+	for {
+		select {
+		case r := <-ln.queue:
+			select {
+			case out <- r:
+			case <-ln.ended:
+				return
+			}
+		case <-ln.ended:
+			return
+		}
+	}
+}
+
+// push queues r for delivery applying the overflow policy,
+// it returns false if the listener was stopped while blocked
+func (ln *Listener) push(l *Light, r *ResultNotification) bool {
+	policy := ln.policy
+	if policy == OverflowDropOldest && cap(ln.queue) == 0 {
+		// Nothing queued to drop in favour of r
+		policy = OverflowDropNewest
+	}
+	switch policy {
+	case OverflowDropNewest:
+		select {
+		case ln.queue <- r:
+		default:
+			l.drop()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case ln.queue <- r:
+				return true
+			default:
+			}
+			select {
+			case <-ln.queue:
+				l.drop()
+			default:
+			}
+		}
+	default:
+		select {
+		case ln.queue <- r:
+		case <-ln.stop:
+			return false
+		}
+	}
+	return true
+}
+
+// drop accounts a discarded message
+func (l *Light) drop() {
+	n := l.dropped.Add(1)
+	l.log().WithField("dropped", n).Warnf("Consumer too slow, message dropped")
+	l.emit(Event{Kind: EventDropped, Dropped: n})
+}
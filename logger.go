@@ -0,0 +1,75 @@
+package yeelight
+
+import "sync"
+
+// Fields are key/value pairs attached to log messages
+type Fields map[string]interface{}
+
+// Logger is the logging interface used by the package, see the
+// logrusadapter package for an implementation using logrus
+type Logger interface {
+	WithField(key string, value interface{}) Logger
+	WithFields(fields Fields) Logger
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// nopLogger discards all messages
+type nopLogger struct{}
+
+func (n nopLogger) WithField(string, interface{}) Logger { return n }
+func (n nopLogger) WithFields(Fields) Logger             { return n }
+func (nopLogger) Debugf(string, ...interface{})          {}
+func (nopLogger) Infof(string, ...interface{})           {}
+func (nopLogger) Warnf(string, ...interface{})           {}
+func (nopLogger) Errorf(string, ...interface{})          {}
+
+// Logger used when none is configured, discarding by default
+var (
+	stdLogMu sync.RWMutex
+	stdLog   Logger = nopLogger{}
+)
+
+// SetLogger sets the package default logger, used by discovery
+// and by lights and managers without their own logger
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	stdLogMu.Lock()
+	stdLog = l
+	stdLogMu.Unlock()
+}
+
+// log returns the package default logger
+func log() Logger {
+	stdLogMu.RLock()
+	defer stdLogMu.RUnlock()
+	return stdLog
+}
+
+// getLogger returns the logger configured in c or the package one
+func (c *config) getLogger() Logger {
+	if c.logger == nil {
+		return log()
+	}
+	return c.logger
+}
+
+// WithLogger sets the logger of a Manager or Light
+func WithLogger(l Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// log returns a logger with light's fields
+func (l *Light) log() Logger {
+	return l.cfg.getLogger().WithFields(Fields{
+		"ID":      l.ID,
+		"address": l.Address,
+		"name":    l.Name,
+	})
+}
@@ -0,0 +1,33 @@
+// Package logrusadapter implements yeelight.Logger with logrus
+package logrusadapter
+
+import (
+	"github.com/pulento/yeelight"
+	"github.com/sirupsen/logrus"
+)
+
+type entry struct {
+	e *logrus.Entry
+}
+
+// New returns a yeelight.Logger writing to l,
+// a nil l uses logrus' standard logger
+func New(l *logrus.Logger) yeelight.Logger {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return entry{logrus.NewEntry(l)}
+}
+
+func (e entry) WithField(key string, value interface{}) yeelight.Logger {
+	return entry{e.e.WithField(key, value)}
+}
+
+func (e entry) WithFields(fields yeelight.Fields) yeelight.Logger {
+	return entry{e.e.WithFields(logrus.Fields(fields))}
+}
+
+func (e entry) Debugf(format string, args ...interface{}) { e.e.Debugf(format, args...) }
+func (e entry) Infof(format string, args ...interface{})  { e.e.Infof(format, args...) }
+func (e entry) Warnf(format string, args ...interface{})  { e.e.Warnf(format, args...) }
+func (e entry) Errorf(format string, args ...interface{}) { e.e.Errorf(format, args...) }
@@ -0,0 +1,259 @@
+package yeelight
+
+import (
+	"context"
+	"io"
+	"iter"
+	"sync"
+	"time"
+)
+
+// Manager keeps the lights found on the network connected
+// and funnels all their results and notifications together
+type Manager struct {
+	localAddr string
+	cfg       config
+	lights    *Lights
+	mu        sync.Mutex
+	listeners map[string]*Listener
+	// Listens dialing, Close waits for them
+	dialing  sync.WaitGroup
+	events   chan *ResultNotification
+	monitor  io.Closer
+	closed   bool
+	stop     chan struct{}
+	handlers *eventFanout
+	sched    *Scheduler
+	presence *Presence
+}
+
+// NewManager returns a Manager searching lights from localAddr,
+// an empty localAddr uses the default interface. opts are
+// applied to the Manager and every light it finds
+func NewManager(localAddr string, opts ...Option) *Manager {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	// Lights get the fan-out so handlers can be added later
+	fan := &eventFanout{}
+	if cfg.events != nil {
+		fan.add(cfg.events)
+	}
+	cfg.events = fan
+	m := &Manager{
+		localAddr: localAddr,
+		cfg:       cfg,
+		lights:    NewLights(),
+		listeners: make(map[string]*Listener),
+		events:    make(chan *ResultNotification, cfg.eventQueue),
+		stop:      make(chan struct{}),
+		handlers:  fan,
+	}
+	m.lights.clock = cfg.clock
+	go m.watchStale()
+	return m
+}
+
+// Search searches lights for wait seconds and starts
+// listening the new ones found
+func (m *Manager) Search(wait int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(wait)*time.Second)
+	defer cancel()
+	return m.Discover(ctx)
+}
+
+// Discover finds lights with Manager's Discoverer until it ends or
+// ctx is done, starting listening the new ones found
+func (m *Manager) Discover(ctx context.Context) (err error) {
+	if m.isClosed() {
+		return errManagerClosed
+	}
+	ctx, sp := m.cfg.getTracer().Start(ctx, "yeelight.discover")
+	defer func() { endSpan(sp, err) }()
+	d := m.cfg.discoverer
+	if d == nil {
+		d = SSDPDiscoverer{LocalAddr: m.localAddr, MulticastAddr: m.cfg.mcastAddr}
+	}
+	found, err := d.Discover(ctx)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for l := range found {
+		if cur, added := m.lights.LoadOrStore(l); !added {
+			// Discoverers may hand over connected lights
+			if cur != l {
+				l.Close()
+			}
+			continue
+		}
+		n++
+		m.discovered(l)
+		m.listen(l)
+	}
+	sp.SetAttrs(Attr{"yeelight.lights.new", n})
+	return nil
+}
+
+// Monitor starts listening lights' SSDP announcements
+// connecting to the new ones found
+func (m *Manager) Monitor() error {
+	if m.isClosed() {
+		return errManagerClosed
+	}
+	mon, err := ssdpMonitor(m.cfg.mcastAddr, m.lights, func(l *Light) {
+		m.mu.Lock()
+		_, listening := m.listeners[l.ID]
+		m.mu.Unlock()
+		if listening {
+			l.emit(Event{Kind: EventDiscovery, Address: l.Address})
+			return
+		}
+		m.discovered(l)
+		m.listen(l)
+	})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.monitor = mon
+	m.mu.Unlock()
+	return nil
+}
+
+// discovered applies Manager's configuration to a new light,
+// with the light's own options on top, and reports it
+func (m *Manager) discovered(l *Light) {
+	l.mu.Lock()
+	cfg := m.cfg
+	for _, o := range l.opts {
+		o(&cfg)
+	}
+	l.cfg = cfg
+	l.mu.Unlock()
+	l.emit(Event{Kind: EventDiscovery, Address: l.Address})
+}
+
+// Add starts managing l, a light not found by the Manager like one
+// loaded from a registry, connecting and listening it. It does
+// nothing for known lights
+func (m *Manager) Add(l *Light) error {
+	if m.isClosed() {
+		return errManagerClosed
+	}
+	if _, added := m.lights.LoadOrStore(l); !added {
+		return nil
+	}
+	m.discovered(l)
+	if err := m.listen(l); err != nil {
+		m.lights.Delete(l.ID)
+		return err
+	}
+	return nil
+}
+
+// listen starts listening device d, Close waits for the
+// dial so it never misses a listener writing to events
+func (m *Manager) listen(d Device) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return errManagerClosed
+	}
+	m.dialing.Add(1)
+	m.mu.Unlock()
+	defer m.dialing.Done()
+
+	id := d.Info().ID
+	ln, err := d.Listen(m.events)
+	if err != nil {
+		m.cfg.getLogger().WithField("ID", id).Errorf("Error connecting: %s", err)
+		return err
+	}
+	m.mu.Lock()
+	m.listeners[id] = ln
+	m.mu.Unlock()
+	go func() {
+		<-ln.Done()
+		m.mu.Lock()
+		if m.listeners[id] == ln {
+			delete(m.listeners, id)
+		}
+		m.mu.Unlock()
+	}()
+	return nil
+}
+
+// Lights returns the collection of devices known by the Manager
+func (m *Manager) Lights() *Lights {
+	return m.lights
+}
+
+// Get returns the light with id or nil if unknown
+func (m *Manager) Get(id string) *Light {
+	return m.lights.Get(id)
+}
+
+// Events returns the channel where results and
+// notifications of all lights are sent
+func (m *Manager) Events() <-chan *ResultNotification {
+	return m.events
+}
+
+// All returns an iterator over a snapshot of the known lights
+func (m *Manager) All() iter.Seq[*Light] {
+	lights := make([]*Light, 0, m.lights.Len())
+	m.lights.Range(func(_ string, l *Light) bool {
+		lights = append(lights, l)
+		return true
+	})
+	return func(yield func(*Light) bool) {
+		for _, l := range lights {
+			if !yield(l) {
+				return
+			}
+		}
+	}
+}
+
+// EventsSeq returns an iterator over lights' results and
+// notifications, it ends when the loop breaks
+func (m *Manager) EventsSeq() iter.Seq[*ResultNotification] {
+	return func(yield func(*ResultNotification) bool) {
+		for e := range m.events {
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// MemoryStats reports the buffers held by a Manager and its lights
+type MemoryStats struct {
+	Lights       int `json:"lights"`
+	ReaderBytes  int `json:"reader_bytes"`
+	HistoryItems int `json:"history_items"`
+	PendingCalls int `json:"pending_calls"`
+	EventsQueued int `json:"events_queued"`
+	EventsCap    int `json:"events_cap"`
+}
+
+// MemoryStats returns a snapshot of the buffers in use
+func (m *Manager) MemoryStats() MemoryStats {
+	st := MemoryStats{
+		EventsQueued: len(m.events),
+		EventsCap:    cap(m.events),
+	}
+	for l := range m.All() {
+		l.mu.Lock()
+		st.Lights++
+		if l.Reader != nil {
+			st.ReaderBytes += l.Reader.Size()
+		}
+		st.HistoryItems += len(l.history)
+		st.PendingCalls += len(l.Calls)
+		l.mu.Unlock()
+	}
+	return st
+}
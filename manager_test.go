@@ -0,0 +1,140 @@
+package yeelight_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/yeelighttest"
+)
+
+func TestDiscoverStatic(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	m := yeelight.NewManager("", yeelight.WithDiscoverer(yeelight.StaticDiscoverer{b.Addr()}))
+	defer m.Close(context.Background())
+
+	// Discovering again must not connect the known light twice
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := m.Discover(ctx)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := m.Lights().Len(); n != 1 {
+		t.Errorf("manager has %d lights, want 1", n)
+	}
+	// Probes close their connection asynchronously
+	deadline := time.Now().Add(time.Second)
+	for b.Conns() != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := b.Conns(); n != 1 {
+		t.Errorf("bulb has %d connections, want 1", n)
+	}
+	if m.Get(b.Addr()) == nil {
+		t.Errorf("light %s not found by address", b.Addr())
+	}
+}
+
+func TestDiscoverLightOptions(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	m := yeelight.NewManager("", yeelight.WithDiscoverer(yeelight.StaticDiscoverer{b.Addr()}),
+		yeelight.WithAutoMusic(3))
+	defer m.Close(context.Background())
+	if err := m.Discover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l := m.Get(b.Addr())
+	if l == nil {
+		t.Fatal("light not discovered")
+	}
+
+	// Manager's options reach the light, which then switches to music mode
+	for i := 1; i <= 5; i++ {
+		if _, err := l.SetBrightness(i, yeelight.Sudden); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for b.Prop("music_on") != "1" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if b.Prop("music_on") != "1" {
+		t.Error("discovered light did not get Manager's WithAutoMusic")
+	}
+}
+
+// strip is a Device other than a Light
+type strip struct {
+	yeelight.Device
+	id string
+}
+
+func (s strip) Info() yeelight.DeviceInfo { return yeelight.DeviceInfo{ID: s.id} }
+func (s strip) Close() error              { return nil }
+
+func TestRestore(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	path := filepath.Join(t.TempDir(), "lights.json")
+
+	m := yeelight.NewManager("", yeelight.WithDiscoverer(yeelight.StaticDiscoverer{b.Addr()}))
+	if err := m.Discover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Lights().SetAlias(b.Addr(), "desk"); err != nil {
+		t.Fatal(err)
+	}
+	m.Lights().Tag(b.Addr(), "office")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	m.Close(context.Background())
+
+	// A light gone and an ID now taken by another kind of device
+	var saved []map[string]interface{}
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	saved = append(saved,
+		map[string]interface{}{"id": "0x02", "address": "127.0.0.1:1"},
+		map[string]interface{}{"id": "0x03", "address": "127.0.0.1:1"})
+	data, _ = json.Marshal(saved)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m = yeelight.NewManager("")
+	defer m.Close(context.Background())
+	m.Lights().LoadOrStoreDevice(strip{id: "0x03"})
+	err := m.Restore(path)
+	if err == nil || !strings.Contains(err.Error(), "0x02") || !strings.Contains(err.Error(), "0x03") {
+		t.Errorf("Restore error %v, want the unreachable and non-light entries", err)
+	}
+	l := m.Lights().Lookup("desk")
+	if l == nil || l.ID != b.Addr() {
+		t.Fatalf("desk = %v, want %s", l, b.Addr())
+	}
+	if tags := m.Lights().Tags(l.ID); len(tags) != 1 || tags[0] != "office" {
+		t.Errorf("tags = %v, want [office]", tags)
+	}
+	// Restored lights are listened without discovery
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := l.Call(ctx, "get_prop", "bright"); err != nil {
+		t.Errorf("restored light not listened: %v", err)
+	}
+	if m.Get("0x02") == nil {
+		t.Error("unreachable light not kept")
+	}
+}
@@ -0,0 +1,148 @@
+package yeelight
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+)
+
+var (
+	mdnsAddress = "224.0.0.251:5353"
+	mdnsService = "_miio._udp.local"
+)
+
+var errBadDNS = errors.New("Malformed DNS message")
+
+// DNS record types used by the mDNS discovery
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+)
+
+// MDNSDiscoverer finds lights announcing themselves with mDNS, as
+// newer firmware does. Each answering host is probed to build its Light
+type MDNSDiscoverer struct{}
+
+// Discover implements Discoverer
+func (MDNSDiscoverer) Discover(ctx context.Context) (<-chan *Light, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteTo(mdnsQuery(mdnsService), raddr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	out := make(chan *Light)
+	go func() {
+		defer close(out)
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+		defer conn.Close()
+		probed := make(map[string]bool)
+		buf := make([]byte, 9000)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			ips, err := mdnsAddresses(buf[:n])
+			if err != nil {
+				continue
+			}
+			for _, ip := range ips {
+				if probed[ip] {
+					continue
+				}
+				probed[ip] = true
+				l, err := Probe(ip)
+				if err != nil {
+					log().WithField("address", ip).Debugf("mDNS host is not a light: %s", err)
+					continue
+				}
+				select {
+				case out <- l:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// mdnsQuery builds a PTR query for service
+func mdnsQuery(service string) []byte {
+	// ID 0, no flags, one question
+	msg := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, label := range strings.Split(service, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0, 0, dnsTypePTR, 0, 1)
+}
+
+// mdnsAddresses returns the IPv4 addresses in the A records of msg
+func mdnsAddresses(msg []byte) ([]string, error) {
+	if len(msg) < 12 {
+		return nil, errBadDNS
+	}
+	qd := int(binary.BigEndian.Uint16(msg[4:]))
+	rr := int(binary.BigEndian.Uint16(msg[6:])) +
+		int(binary.BigEndian.Uint16(msg[8:])) +
+		int(binary.BigEndian.Uint16(msg[10:]))
+	off := 12
+	var err error
+	for i := 0; i < qd; i++ {
+		if off, err = skipDNSName(msg, off); err != nil {
+			return nil, err
+		}
+		off += 4
+	}
+	var ips []string
+	for i := 0; i < rr; i++ {
+		if off, err = skipDNSName(msg, off); err != nil {
+			return nil, err
+		}
+		if off+10 > len(msg) {
+			return nil, errBadDNS
+		}
+		typ := binary.BigEndian.Uint16(msg[off:])
+		rdlen := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+		if off+rdlen > len(msg) {
+			return nil, errBadDNS
+		}
+		if typ == dnsTypeA && rdlen == 4 {
+			ips = append(ips, net.IP(msg[off:off+4]).String())
+		}
+		off += rdlen
+	}
+	return ips, nil
+}
+
+// skipDNSName returns the offset after the name starting at off
+func skipDNSName(msg []byte, off int) (int, error) {
+	for {
+		if off >= len(msg) {
+			return 0, errBadDNS
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, nil
+		case n&0xc0 == 0xc0:
+			// Compression pointer ends the name
+			return off + 2, nil
+		default:
+			off += n + 1
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package yeelight
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// Port of the MiIO protocol
+	miioPort = "54321"
+	// Size of MiIO packets' header
+	miioHeaderLen = 32
+	// Time the MiIO handshake may take
+	miioTimeout = 5 * time.Second
+)
+
+// WithMiIO makes lights use the MiIO protocol with token, a 32
+// characters hex string, instead of the LAN control port. It is a
+// fallback for lights with LAN control disabled, they don't send
+// notifications so their state is only updated by refreshes
+func WithMiIO(token string) Option {
+	return WithTransport(func() Transport {
+		return &miioTransport{token: token}
+	})
+}
+
+// miioTransport is a Transport over MiIO, lights understand the same
+// commands on it as on the LAN control port but encrypted over UDP
+type miioTransport struct {
+	token    string
+	mu       sync.Mutex
+	conn     *net.UDPConn
+	key, iv  []byte
+	tok      []byte
+	deviceID uint32
+	stamp    uint32
+	stampAt  time.Time
+}
+
+// Dial implements Transport, addr's port is replaced by MiIO's
+func (t *miioTransport) Dial(addr string) error {
+	tok, err := hex.DecodeString(t.token)
+	if err != nil || len(tok) != 16 {
+		return fmt.Errorf("%w: MiIO token must be 32 hex characters", errInvalidParam)
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	raddr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(addr, miioPort))
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return err
+	}
+	// The hello answer carries the device ID and its clock
+	hello := make([]byte, miioHeaderLen)
+	binary.BigEndian.PutUint16(hello, 0x2131)
+	binary.BigEndian.PutUint16(hello[2:], miioHeaderLen)
+	for i := 4; i < miioHeaderLen; i++ {
+		hello[i] = 0xff
+	}
+	conn.SetDeadline(time.Now().Add(miioTimeout))
+	buf := make([]byte, miioHeaderLen)
+	if _, err := conn.Write(hello); err != nil {
+		conn.Close()
+		return err
+	}
+	if n, err := conn.Read(buf); err != nil || n < miioHeaderLen {
+		conn.Close()
+		if err == nil {
+			err = errMiIOPacket
+		}
+		return fmt.Errorf("MiIO handshake: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	key := md5.Sum(tok)
+	iv := md5.Sum(append(key[:], tok...))
+	t.mu.Lock()
+	old := t.conn
+	t.conn, t.tok, t.key, t.iv = conn, tok, key[:], iv[:]
+	t.deviceID = binary.BigEndian.Uint32(buf[8:])
+	t.stamp = binary.BigEndian.Uint32(buf[12:])
+	t.stampAt = time.Now()
+	t.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// Send implements Transport
+func (t *miioTransport) Send(b []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return errNotConnected
+	}
+	payload, err := t.encrypt(bytes.TrimSuffix(b, endOfCommand))
+	if err != nil {
+		return err
+	}
+	p := make([]byte, miioHeaderLen, miioHeaderLen+len(payload))
+	binary.BigEndian.PutUint16(p, 0x2131)
+	binary.BigEndian.PutUint16(p[2:], uint16(miioHeaderLen+len(payload)))
+	binary.BigEndian.PutUint32(p[8:], t.deviceID)
+	binary.BigEndian.PutUint32(p[12:], t.stamp+uint32(time.Since(t.stampAt)/time.Second))
+	// The checksum is computed with the token in its place
+	copy(p[16:], t.tok)
+	p = append(p, payload...)
+	sum := md5.Sum(p)
+	copy(p[16:], sum[:])
+	_, err = t.conn.Write(p)
+	return err
+}
+
+// Receive implements Transport
+func (t *miioTransport) Receive(v interface{}) error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn == nil {
+		return errNotConnected
+	}
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+		if n <= miioHeaderLen {
+			// Empty acks carry nothing to decode
+			continue
+		}
+		t.mu.Lock()
+		data, err := t.decrypt(buf[miioHeaderLen:n])
+		t.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		// Some firmware pad the JSON with NULs
+		return json.Unmarshal(bytes.TrimRight(data, "\x00"), v)
+	}
+}
+
+// Close implements Transport
+func (t *miioTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return errNotConnected
+	}
+	return t.conn.Close()
+}
+
+// SetReadDeadline bounds the Receives in course and to come
+func (t *miioTransport) SetReadDeadline(d time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return errNotConnected
+	}
+	return t.conn.SetReadDeadline(d)
+}
+
+// encrypt encrypts b with AES-128-CBC and PKCS#7 padding
+func (t *miioTransport) encrypt(b []byte) ([]byte, error) {
+	block, err := aes.NewCipher(t.key)
+	if err != nil {
+		return nil, err
+	}
+	pad := aes.BlockSize - len(b)%aes.BlockSize
+	out := append(append([]byte{}, b...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+	cipher.NewCBCEncrypter(block, t.iv).CryptBlocks(out, out)
+	return out, nil
+}
+
+// decrypt reverses encrypt
+func (t *miioTransport) decrypt(b []byte) ([]byte, error) {
+	if len(b)%aes.BlockSize != 0 {
+		return nil, errMiIOPacket
+	}
+	block, err := aes.NewCipher(t.key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(b))
+	cipher.NewCBCDecrypter(block, t.iv).CryptBlocks(out, b)
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > aes.BlockSize || pad > len(out) {
+		return nil, errMiIOPacket
+	}
+	return out[:len(out)-pad], nil
+}
@@ -0,0 +1,138 @@
+package yeelight
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PingMethod is the request used to check a light is responding
+type PingMethod int
+
+// Ping probes
+const (
+	// PingPower queries just the power property, fastest on most firmware
+	PingPower PingMethod = iota
+	// PingEmpty sends a get_prop without properties, the cheapest reply
+	// but badly handled by older firmware
+	PingEmpty
+)
+
+// Model describes the capabilities and parameter ranges of a light model
+type Model struct {
+	Name       string `json:"name"`
+	MinCT      int    `json:"min_ct"`
+	MaxCT      int    `json:"max_ct"`
+	MinBright  int    `json:"min_bright"`
+	MaxBright  int    `json:"max_bright"`
+	Color      bool   `json:"color"`
+	Background bool   `json:"background"`
+	Nightlight bool   `json:"nightlight"`
+	// Ping is the probe used from PingMinFW firmware on, older
+	// firmware falls back to PingPower
+	Ping      PingMethod `json:"ping"`
+	PingMinFW int        `json:"ping_min_fw"`
+}
+
+// genericModel is used for models not found in the registry
+var genericModel = Model{
+	Name:      "generic",
+	MinCT:     1700,
+	MaxCT:     6500,
+	MinBright: 1,
+	MaxBright: 100,
+	Color:     true,
+}
+
+var (
+	modelsMu sync.RWMutex
+	models   = map[string]*Model{
+		"mono":      {Name: "mono", MinCT: 2700, MaxCT: 2700, MinBright: 1, MaxBright: 100},
+		"mono1":     {Name: "mono1", MinCT: 2700, MaxCT: 2700, MinBright: 1, MaxBright: 100},
+		"ct_bulb":   {Name: "ct_bulb", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100},
+		"color":     {Name: "color", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"color1":    {Name: "color1", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"color2":    {Name: "color2", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 9, Color: true},
+		"stripe":    {Name: "stripe", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"strip1":    {Name: "strip1", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"ceiling":   {Name: "ceiling", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Nightlight: true},
+		"ceiling1":  {Name: "ceiling1", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Nightlight: true},
+		"ceiling2":  {Name: "ceiling2", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Nightlight: true},
+		"ceiling3":  {Name: "ceiling3", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Nightlight: true},
+		"ceiling4":  {Name: "ceiling4", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Background: true, Nightlight: true},
+		"bslamp":    {Name: "bslamp", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"bslamp1":   {Name: "bslamp1", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Color: true},
+		"desklamp":  {Name: "desklamp", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100},
+		"lamp1":     {Name: "lamp1", MinCT: 2700, MaxCT: 5000, MinBright: 1, MaxBright: 100},
+		"ceila":     {Name: "ceila", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Nightlight: true},
+		"bslamp2":   {Name: "bslamp2", MinCT: 1700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 9, Color: true, Nightlight: true},
+		"ceiling10": {Name: "ceiling10", MinCT: 2700, MaxCT: 6500, MinBright: 1, MaxBright: 100, Ping: PingEmpty, PingMinFW: 33, Background: true, Nightlight: true},
+	}
+)
+
+// LookupModel returns the description of model name, unknown models
+// get a permissive generic description so they are not restricted
+func LookupModel(name string) *Model {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	if m, ok := models[name]; ok {
+		return m
+	}
+	m := genericModel
+	m.Name = name
+	return &m
+}
+
+// RegisterModel adds or replaces a model description
+func RegisterModel(m *Model) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	models[m.Name] = m
+}
+
+// ModelSpec returns the capabilities of light's model
+// adjusted by the quirks of its firmware
+func (l *Light) ModelSpec() *Model {
+	m := LookupModel(l.Model)
+	for _, q := range l.quirksFor() {
+		if q.Adjust != nil {
+			adjusted := *m
+			q.Adjust(&adjusted)
+			m = &adjusted
+		}
+	}
+	return m
+}
+
+// pingParams returns the get_prop parameters of the probe for firmware fw
+func (m *Model) pingParams(fw int) []interface{} {
+	if m.Ping == PingEmpty && fw >= m.PingMinFW {
+		return []interface{}{""}
+	}
+	return []interface{}{"power"}
+}
+
+func (m *Model) validBright(bright int) error {
+	if bright < m.MinBright || bright > m.MaxBright {
+		return fmt.Errorf("%w: brightness %d out of range %d-%d for model %s",
+			errInvalidParam, bright, m.MinBright, m.MaxBright, m.Name)
+	}
+	return nil
+}
+
+func (m *Model) validCT(ct int) error {
+	if ct < m.MinCT || ct > m.MaxCT {
+		return fmt.Errorf("%w: color temperature %d out of range %d-%d for model %s",
+			errInvalidParam, ct, m.MinCT, m.MaxCT, m.Name)
+	}
+	return nil
+}
+
+func (m *Model) validRGB(rgb uint32) error {
+	if !m.Color {
+		return fmt.Errorf("%w: model %s has no color support", errCommandNotSupported, m.Name)
+	}
+	if rgb > 0xffffff {
+		return fmt.Errorf("%w: rgb %#x out of range 0-0xffffff", errInvalidParam, rgb)
+	}
+	return nil
+}
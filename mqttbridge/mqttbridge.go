@@ -0,0 +1,173 @@
+// Package mqttbridge bridges a yeelight.Registry to an MQTT broker,
+// for home automation hubs speaking MQTT. Topics are under a prefix:
+//
+//	<prefix>/status            "online", or "offline" as last will
+//	<prefix>/<id>/state        retained light state, a yeelight.LightJSON
+//	<prefix>/<id>/set/power    {"on": true, "duration": 500}
+//	<prefix>/<id>/set/bright   {"bright": 50, "duration": 500}
+//	<prefix>/<id>/set/color    {"rgb": 16711680} or {"hue": 120, "sat": 100} or {"ct": 2700}
+//	<prefix>/<id>/set/scene    {"class": "color", "values": [16711680, 100]}
+//	<prefix>/<id>/set/effect   a yeelight.Flow, an empty payload stops the running flow
+//	<prefix>/<id>/error        why the last command failed
+//
+// Set topics address lights by ID, alias or name, the bodies are the
+// ones of package httpapi. States are published under the light ID.
+package mqttbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/internal/lightcmd"
+)
+
+const (
+	// DefaultPrefix is the topic prefix used when none is given
+	DefaultPrefix = "yeelight"
+	// Default time a command waits for the light
+	defaultTimeout = 5 * time.Second
+	// Commands and states are delivered at least once
+	qos = 1
+)
+
+// Commands of the set topics
+var commands = map[string]lightcmd.Builder{
+	"power":  lightcmd.Power,
+	"bright": lightcmd.Bright,
+	"color":  lightcmd.Color,
+	"scene":  lightcmd.Scene,
+	"effect": lightcmd.Effect,
+}
+
+// Client is the part of an MQTT client used by a Bridge,
+// satisfied by paho's mqtt.Client
+type Client interface {
+	Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token
+	Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token
+}
+
+// Bridge publishes the state of a Registry's lights and runs the
+// commands received. It is also an EventHandler, add it to the
+// Manager with AddEventHandler to publish state changes
+type Bridge struct {
+	reg    *yeelight.Registry
+	prefix string
+	// Timeout bounds how long a command waits for the light
+	Timeout time.Duration
+
+	mu sync.Mutex
+	c  Client
+}
+
+// New returns a Bridge for the lights in reg publishing under
+// prefix, an empty prefix uses DefaultPrefix
+func New(reg *yeelight.Registry, prefix string) *Bridge {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &Bridge{reg: reg, prefix: strings.TrimSuffix(prefix, "/"), Timeout: defaultTimeout}
+}
+
+// StatusTopic returns the topic of the bridge availability, meant
+// for the client's last will
+func (b *Bridge) StatusTopic() string {
+	return b.prefix + "/status"
+}
+
+// Start bridges over the connected client c subscribing to the set
+// topics and publishing the state of the known lights. Call it from
+// the client's OnConnect handler so it runs again after reconnecting
+func (b *Bridge) Start(c Client) error {
+	b.mu.Lock()
+	b.c = c
+	b.mu.Unlock()
+	t := c.Subscribe(b.prefix+"/+/set/+", qos, b.handle)
+	if t.Wait(); t.Error() != nil {
+		return fmt.Errorf("subscribe: %w", t.Error())
+	}
+	c.Publish(b.StatusTopic(), qos, true, "online")
+	for _, l := range b.reg.Query() {
+		b.publishState(l)
+	}
+	return nil
+}
+
+// publish sends payload to topic without waiting,
+// nothing is sent before Start
+func (b *Bridge) publish(topic string, retained bool, payload interface{}) {
+	b.mu.Lock()
+	c := b.c
+	b.mu.Unlock()
+	if c != nil {
+		c.Publish(topic, qos, retained, payload)
+	}
+}
+
+// HandleEvent implements yeelight.EventHandler publishing
+// the state of the lights changing
+func (b *Bridge) HandleEvent(e yeelight.Event) {
+	switch e.Kind {
+	case yeelight.EventNotification, yeelight.EventStatus, yeelight.EventDiscovery:
+		if l := b.reg.Get(e.DevID); l != nil {
+			b.publishState(l)
+		}
+	}
+}
+
+// publishState publishes the retained state of l without waiting
+func (b *Bridge) publishState(l *yeelight.Light) {
+	j, err := json.Marshal(l.JSON())
+	if err != nil {
+		return
+	}
+	b.publish(b.prefix+"/"+l.ID+"/state", true, j)
+}
+
+// handle runs the command of a set topic, apart as paho
+// delivers messages one at a time
+func (b *Bridge) handle(_ mqtt.Client, m mqtt.Message) {
+	parts := strings.Split(strings.TrimPrefix(m.Topic(), b.prefix+"/"), "/")
+	if len(parts) != 3 || parts[1] != "set" {
+		return
+	}
+	go b.run(parts[0], parts[2], m.Payload())
+}
+
+// run sends command to the light ref, failures are
+// published on the error topic
+func (b *Bridge) run(ref, command string, payload []byte) {
+	if err := b.command(ref, command, payload); err != nil {
+		b.publish(b.prefix+"/"+ref+"/error", false, err.Error())
+	}
+}
+
+func (b *Bridge) command(ref, command string, payload []byte) error {
+	l := b.reg.Lookup(ref)
+	if l == nil {
+		return fmt.Errorf("unknown light %q", ref)
+	}
+	build, ok := commands[command]
+	if command == "effect" && len(payload) == 0 {
+		build, ok = lightcmd.StopEffect, true
+	}
+	if !ok {
+		return fmt.Errorf("unknown command %q", command)
+	}
+	method, params, err := build(payload)
+	if err != nil {
+		return err
+	}
+	if !l.Support[method] {
+		return fmt.Errorf("light does not support %s", method)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.Timeout)
+	defer cancel()
+	_, err = l.Call(ctx, method, params...)
+	return err
+}
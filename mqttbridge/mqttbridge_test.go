@@ -0,0 +1,116 @@
+package mqttbridge_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/mqttbridge"
+	"github.com/pulento/yeelight/yeelighttest"
+)
+
+// client is a broker-less mqttbridge.Client keeping the last
+// payload of every topic
+type client struct {
+	mu      sync.Mutex
+	topics  map[string]string
+	handler mqtt.MessageHandler
+}
+
+func (c *client) Publish(topic string, _ byte, _ bool, payload interface{}) mqtt.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[topic] = fmt.Sprintf("%s", payload)
+	return done{}
+}
+
+func (c *client) Subscribe(_ string, _ byte, h mqtt.MessageHandler) mqtt.Token {
+	c.handler = h
+	return done{}
+}
+
+// wait returns the payload of topic once published
+func (c *client) wait(t *testing.T, topic string) string {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		c.mu.Lock()
+		p, ok := c.topics[topic]
+		c.mu.Unlock()
+		if ok {
+			return p
+		}
+	}
+	t.Fatalf("nothing published on %s", topic)
+	return ""
+}
+
+type done struct{}
+
+func (done) Wait() bool                     { return true }
+func (done) WaitTimeout(time.Duration) bool { return true }
+func (done) Done() <-chan struct{}          { c := make(chan struct{}); close(c); return c }
+func (done) Error() error                   { return nil }
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+func (m message) Duplicate() bool   { return false }
+func (m message) Qos() byte         { return 1 }
+func (m message) Retained() bool    { return false }
+func (m message) Topic() string     { return m.topic }
+func (m message) MessageID() uint16 { return 1 }
+func (m message) Payload() []byte   { return m.payload }
+func (m message) Ack()              {}
+
+func TestBridge(t *testing.T) {
+	b := yeelighttest.NewBulb()
+	defer b.Close()
+	m := yeelight.NewManager("", yeelight.WithDiscoverer(yeelight.StaticDiscoverer{b.Addr()}))
+	defer m.Close(context.Background())
+	if err := m.Discover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c := &client{topics: make(map[string]string)}
+	br := mqttbridge.New(m.Lights(), "home/lights")
+	if err := br.Start(c); err != nil {
+		t.Fatal(err)
+	}
+	m.AddEventHandler(br)
+	state := "home/lights/" + b.Addr() + "/state"
+
+	var j yeelight.LightJSON
+	if err := json.Unmarshal([]byte(c.wait(t, state)), &j); err != nil || j.Bright != 100 {
+		t.Fatalf("state %+v, %v, want bright 100", j, err)
+	}
+	if c.wait(t, "home/lights/status") != "online" {
+		t.Error("bridge not online")
+	}
+
+	c.handler(nil, message{"home/lights/" + b.Addr() + "/set/bright", []byte(`{"bright": 20}`)})
+	deadline := time.Now().Add(time.Second)
+	for b.Prop("bright") != "20" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if b.Prop("bright") != "20" {
+		t.Errorf("bulb bright = %s, want 20", b.Prop("bright"))
+	}
+	// The light's notification republishes its state
+	for deadline := time.Now().Add(time.Second); j.Bright != 20 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		json.Unmarshal([]byte(c.wait(t, state)), &j)
+	}
+	if j.Bright != 20 {
+		t.Errorf("published bright = %d, want 20", j.Bright)
+	}
+
+	c.handler(nil, message{"home/lights/desk/set/power", []byte(`{"on": false}`)})
+	if got := c.wait(t, "home/lights/desk/error"); got != `unknown light "desk"` {
+		t.Errorf("error = %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package yeelight
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StartMusic switches light to music mode, the light connects back
+// to localIP and state changes sent afterwards have no quota nor
+// results, queries like get_prop still use the control connection.
+// An empty localIP uses the address of the control connection
+func (l *Light) StartMusic(localIP string) error {
+	if l.transport == nil {
+		return errNotConnected
+	}
+	if localIP == "" {
+		t, ok := l.transport.(interface{ LocalAddr() net.Addr })
+		if !ok {
+			return errNoLocalAddr
+		}
+		addr, ok := t.LocalAddr().(*net.TCPAddr)
+		if !ok {
+			return errNoLocalAddr
+		}
+		localIP = addr.IP.String()
+	}
+	ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP(localIP)})
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+	if _, err := l.SendCommand("set_music", 1, localIP, port); err != nil {
+		return err
+	}
+	ln.SetDeadline(time.Now().Add(l.cfg.connTimeout))
+	cn, err := ln.AcceptTCP()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.music = cn
+	l.musicAuto = false
+	l.mu.Unlock()
+	l.log().WithField("port", strconv.Itoa(port)).Debugf("Music mode started")
+	return nil
+}
+
+// StopMusic leaves music mode returning to quota limited commands
+func (l *Light) StopMusic() error {
+	l.mu.Lock()
+	music := l.music
+	l.music = nil
+	l.mu.Unlock()
+	if music == nil {
+		return nil
+	}
+	music.Close()
+	_, err := l.SendCommand("set_music", 0)
+	return err
+}
+
+// musicConn returns the music mode connection or nil if not in music mode
+func (l *Light) musicConn() net.Conn {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.music
+}
+
+// viaMusic returns true for the methods sent on the music connection
+// in music mode, the state changes whose "ok" nobody misses. Queries
+// stay on the control connection as lights only answer them there
+func viaMusic(method string) bool {
+	m := strings.TrimPrefix(method, "bg_")
+	return method != "set_music" &&
+		(strings.HasPrefix(m, "set_") || strings.HasPrefix(m, "adjust_") || okMethods[method])
+}
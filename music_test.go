@@ -0,0 +1,91 @@
+package yeelight_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/yeelighttest"
+)
+
+// waitProp waits for the bulb's property name to become value
+func waitProp(t *testing.T, b *yeelighttest.Bulb, name, value string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for b.Prop(name) != value && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := b.Prop(name); got != value {
+		t.Fatalf("bulb %s = %s, want %s", name, got, value)
+	}
+}
+
+func TestMusicRouting(t *testing.T) {
+	b := yeelighttest.NewUnstartedBulb()
+	b.Quota = 3
+	b.Start()
+	l := listen(t, b, yeelight.WithConnectTimeout(time.Second))
+	if err := l.StartMusic(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// State changes go to the music connection, beyond the quota
+	for i := 1; i <= 10; i++ {
+		if _, err := l.SetBrightness(i, yeelight.Sudden); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitProp(t, b, "bright", "10")
+
+	// Queries are answered on the control connection
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := l.Call(ctx, "get_prop", "bright")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Result) != 1 || r.Result[0] != strconv.Itoa(10) {
+		t.Errorf("get_prop bright = %v, want [10]", r.Result)
+	}
+	if err := l.Refresh(ctx); err != nil {
+		t.Errorf("Refresh in music mode: %v", err)
+	}
+}
+
+func TestAutoMusicQueries(t *testing.T) {
+	b := yeelighttest.NewUnstartedBulb()
+	b.Quota = 5
+	b.Start()
+	reconnected, reconnects := events(yeelight.EventReconnect)
+	l := listen(t, b, reconnected, yeelight.WithAutoMusic(3), yeelight.WithConnectTimeout(time.Second),
+		yeelight.WithLivenessCheck(50*time.Millisecond, 200*time.Millisecond))
+	for i := 1; i <= 5; i++ {
+		if _, err := l.SetBrightness(i, yeelight.Sudden); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitProp(t, b, "music_on", "1")
+	waitProp(t, b, "bright", "5")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := l.Call(ctx, "get_prop", "bright")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Result) != 1 || r.Result[0] != "5" {
+		t.Errorf("get_prop bright = %v, want [5]", r.Result)
+	}
+	// Liveness pings are answered, the control connection stays
+	time.Sleep(500 * time.Millisecond)
+	select {
+	case <-reconnects:
+		t.Error("light reconnected in music mode")
+	default:
+	}
+	if b.Prop("music_on") != "1" {
+		t.Error("music mode lost")
+	}
+}
@@ -0,0 +1,129 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Port where lights accept control connections
+const controlPort = "55443"
+
+// Properties fetched by NewLight, lights answer "" for the ones
+// their firmware does not know
+var identityProps = []string{
+	"id", "model", "fw_ver", "name", "power", "bright",
+	"ct", "rgb", "hue", "sat", "color_mode",
+}
+
+// NewLight returns a connected light at addr, an IP with optional
+// port, without using SSDP. Its state is fetched with get_prop, when
+// the firmware does not report its ID the address is used instead.
+// As the support list is unknown every command is allowed
+func NewLight(addr string, opts ...Option) (*Light, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, controlPort)
+	}
+	l := newLight(addr)
+	l.ID = addr
+	for _, name := range capabilityNames {
+		l.Support[name] = true
+	}
+	l.Configure(opts...)
+	if err := l.Connect(); err != nil {
+		return nil, err
+	}
+	vals, err := l.getPropSync(identityProps...)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	str := func(k string) string { return vals[k] }
+	num := func(k string) int {
+		n, _ := strconv.Atoi(vals[k])
+		return n
+	}
+	if id := str("id"); id != "" {
+		l.ID = id
+	}
+	l.Model = str("model")
+	l.FW = num("fw_ver")
+	l.Name = str("name")
+	l.Power = str("power")
+	l.Bright = num("bright")
+	l.CT = num("ct")
+	l.RGB = num("rgb")
+	l.Hue = num("hue")
+	l.Sat = num("sat")
+	l.ColorMode = num("color_mode")
+	return l, nil
+}
+
+// probeLight returns the light at addr like NewLight but
+// disconnected, lights sent by discoverers are connected once
+// by the Manager with its own configuration
+func probeLight(addr string) (*Light, error) {
+	l, err := NewLight(addr)
+	if err != nil {
+		return nil, err
+	}
+	l.Close()
+	l.transport, l.Conn, l.Reader = nil, nil, nil
+	return l, nil
+}
+
+// newLight returns an unconnected light at addr without properties
+func newLight(addr string) *Light {
+	return &Light{
+		Address:      addr,
+		Support:      make(map[string]bool),
+		Calls:        make(map[int32]*Command),
+		ResC:         make(chan *Result),
+		cfg:          defaultConfig(),
+		resetRefresh: make(chan struct{}, 1),
+	}
+}
+
+// getPropSync asks for props reading the answer from the connection
+// itself, for lights not being listened yet
+func (l *Light) getPropSync(props ...string) (map[string]string, error) {
+	params := make([]interface{}, len(props))
+	for i, p := range props {
+		params[i] = p
+	}
+	id, err := l.GetProp(params...)
+	if err != nil {
+		return nil, err
+	}
+	defer l.untrack(id)
+	if t, ok := l.transport.(interface{ SetReadDeadline(time.Time) error }); ok {
+		t.SetReadDeadline(time.Now().Add(l.cfg.commandTimeout))
+		defer t.SetReadDeadline(time.Time{})
+	}
+	for {
+		resnot, err := l.readMessage()
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r := resnot.Result
+		if r == nil || int32(r.ID) != id {
+			continue
+		}
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+		vals := make(map[string]string, len(props))
+		for i, p := range props {
+			if i < len(r.Result) {
+				vals[p] = propString(r.Result[i])
+			}
+		}
+		return vals, nil
+	}
+}
@@ -0,0 +1,136 @@
+package yeelight
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// PropertyEvent is a change of a light's property, values are
+// formatted as the light sends them
+type PropertyEvent struct {
+	DevID string    `json:"id"`
+	Prop  string    `json:"prop"`
+	Old   string    `json:"old"`
+	New   string    `json:"new"`
+	At    time.Time `json:"at"`
+}
+
+func (l *Light) propertyEvent(prop, old, new string) PropertyEvent {
+	return PropertyEvent{DevID: l.ID, Prop: prop, Old: old, New: new, At: l.now()}
+}
+
+// OnChange registers f to be called when prop changes, an empty
+// prop matches all properties. f runs on the listener goroutine
+func (l *Light) OnChange(prop string, f func(PropertyEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.propHandlers == nil {
+		l.propHandlers = make(map[string][]func(PropertyEvent))
+	}
+	l.propHandlers[prop] = append(l.propHandlers[prop], f)
+}
+
+// OnIntChange registers f to be called with the old and new
+// values when the numeric property prop changes
+func (l *Light) OnIntChange(prop string, f func(old, new int)) {
+	l.OnChange(prop, func(e PropertyEvent) {
+		o, _ := strconv.Atoi(e.Old)
+		n, _ := strconv.Atoi(e.New)
+		f(o, n)
+	})
+}
+
+// PropertyEvents returns a channel receiving the changes of props,
+// all properties if none given, until ctx is done, then it is
+// closed. Events are dropped if more than buffer are pending
+func (l *Light) PropertyEvents(ctx context.Context, buffer int, props ...string) <-chan PropertyEvent {
+	c := make(chan PropertyEvent, buffer)
+	s := l.subscribe(ctx, "property", func() int { return len(c) }, func() { close(c) })
+	send := func(e PropertyEvent) {
+		s.send(func() {
+			select {
+			case c <- e:
+			default:
+			}
+		})
+	}
+	if len(props) == 0 {
+		props = []string{""}
+	}
+	for _, p := range props {
+		l.OnChange(p, send)
+	}
+	return c
+}
+
+// Change is the old and new values of a property
+type Change struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// StateChange groups the properties changed by a notification
+type StateChange struct {
+	DevID   string            `json:"id"`
+	Changed map[string]Change `json:"changed"`
+	At      time.Time         `json:"at"`
+}
+
+// OnStateChange registers f to be called once per notification
+// that changed at least one property
+func (l *Light) OnStateChange(f func(StateChange)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stateHandlers = append(l.stateHandlers, f)
+}
+
+// StateChanges returns a channel receiving light's state changes
+// until ctx is done, then it is closed. Changes are dropped if
+// more than buffer are pending
+func (l *Light) StateChanges(ctx context.Context, buffer int) <-chan StateChange {
+	c := make(chan StateChange, buffer)
+	s := l.subscribe(ctx, "state", func() int { return len(c) }, func() { close(c) })
+	l.OnStateChange(func(sc StateChange) {
+		s.send(func() {
+			select {
+			case c <- sc:
+			default:
+			}
+		})
+	})
+	return c
+}
+
+// notifyChanges calls the handlers registered for changes
+func (l *Light) notifyChanges(changes []PropertyEvent) {
+	if len(changes) == 0 {
+		return
+	}
+	sc := StateChange{
+		DevID:   l.ID,
+		Changed: make(map[string]Change, len(changes)),
+		At:      changes[0].At,
+	}
+	for _, e := range changes {
+		sc.Changed[e.Prop] = Change{Old: e.Old, New: e.New}
+	}
+	l.recordState(sc)
+	l.mu.Lock()
+	stateHandlers := append([]func(StateChange){}, l.stateHandlers...)
+	l.mu.Unlock()
+	for _, f := range stateHandlers {
+		f(sc)
+	}
+
+	for _, e := range changes {
+		l.mu.Lock()
+		var handlers []func(PropertyEvent)
+		handlers = append(handlers, l.propHandlers[e.Prop]...)
+		handlers = append(handlers, l.propHandlers[""]...)
+		l.mu.Unlock()
+		for _, f := range handlers {
+			f(e)
+		}
+	}
+}
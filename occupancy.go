@@ -0,0 +1,165 @@
+package yeelight
+
+import (
+	"sort"
+	"time"
+)
+
+// IdlePolicy is how the lights of a room follow its occupancy
+type IdlePolicy struct {
+	// On turns the lights on when the room gets occupied,
+	// at Bright unless it is zero
+	On     bool `json:"on"`
+	Bright int  `json:"bright,omitempty"`
+	// DimAfter of the room being vacant lights are dimmed to DimBright
+	DimAfter  time.Duration `json:"dim_after,omitempty"`
+	DimBright int           `json:"dim_bright,omitempty"`
+	// OffAfter of the room being vacant lights are turned off
+	OffAfter time.Duration `json:"off_after,omitempty"`
+}
+
+// room is a zone whose lights follow an IdlePolicy
+type room struct {
+	group *Group
+	// idle is closed to cancel the idle timeouts running
+	idle chan struct{}
+}
+
+// SetIdlePolicy sets the policy of room, an empty room sets
+// the default policy of the rooms without their own
+func (p *Presence) SetIdlePolicy(room string, policy IdlePolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policies[room] = policy
+}
+
+// AddRoom makes g's lights follow the occupancy of zone g.Name
+func (p *Presence) AddRoom(g *Group) {
+	p.mu.Lock()
+	_, known := p.rooms[g.Name]
+	p.rooms[g.Name] = &room{group: g}
+	p.mu.Unlock()
+	if !known {
+		p.On(g.Name, p.occupancy)
+	}
+}
+
+// Close cancels the idle timeouts running
+func (p *Presence) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range p.rooms {
+		if r.idle != nil {
+			close(r.idle)
+			r.idle = nil
+		}
+	}
+}
+
+// policy returns the policy of room, p.mu must be held
+func (p *Presence) policy(room string) IdlePolicy {
+	if policy, ok := p.policies[room]; ok {
+		return policy
+	}
+	return p.policies[""]
+}
+
+// occupancy applies the policy of e's room
+func (p *Presence) occupancy(e PresenceEvent) {
+	p.mu.Lock()
+	r := p.rooms[e.Zone]
+	if r == nil {
+		p.mu.Unlock()
+		return
+	}
+	policy := p.policy(e.Zone)
+	if r.idle != nil {
+		close(r.idle)
+		r.idle = nil
+	}
+	var idle chan struct{}
+	if !e.Arrived && (policy.DimAfter > 0 || policy.OffAfter > 0) {
+		idle = make(chan struct{})
+		r.idle = idle
+	}
+	lights := append([]*Light(nil), r.group.Lights...)
+	p.mu.Unlock()
+
+	switch {
+	case e.Arrived && policy.On:
+		for _, l := range lights {
+			if _, err := l.SetPower(true, Sudden); err != nil {
+				l.log().WithField("error", err).Errorf("Presence cannot turn on")
+				continue
+			}
+			if policy.Bright > 0 {
+				l.SetBrightness(policy.Bright, Sudden)
+			}
+		}
+	case idle != nil:
+		go p.idle(e.At, policy, lights, idle)
+	}
+}
+
+// idle dims and turns off lights after the timeouts of policy
+// counted from since, unless cancel is closed before
+func (p *Presence) idle(since time.Time, policy IdlePolicy, lights []*Light, cancel chan struct{}) {
+	type step struct {
+		after time.Duration
+		do    func(*Light) error
+	}
+	var steps []step
+	if policy.DimAfter > 0 {
+		steps = append(steps, step{policy.DimAfter, func(l *Light) error {
+			_, err := l.SetBrightness(policy.DimBright, Sudden)
+			return err
+		}})
+	}
+	if policy.OffAfter > 0 {
+		steps = append(steps, step{policy.OffAfter, func(l *Light) error {
+			_, err := l.SetPower(false, Sudden)
+			return err
+		}})
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].after < steps[j].after })
+	for _, s := range steps {
+		select {
+		case <-cancel:
+			return
+		case <-p.clock.After(since.Add(s.after).Sub(p.clock.Now())):
+		}
+		for _, l := range lights {
+			l.mu.Lock()
+			off := l.PowerState() == OFF
+			l.mu.Unlock()
+			if off {
+				continue
+			}
+			if err := s.do(l); err != nil {
+				l.log().WithField("error", err).Errorf("Presence cannot dim or turn off")
+			}
+		}
+	}
+}
+
+// Presence returns the Manager's presence dispatcher, using its clock
+func (m *Manager) Presence() *Presence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.presence == nil {
+		m.presence = NewPresence()
+		m.presence.clock = m.cfg.clock
+	}
+	return m.presence
+}
+
+// Occupancy is the hook for presence sensors, it reports whether
+// room is occupied applying its IdlePolicy to its lights
+func (m *Manager) Occupancy(room string, occupied bool) {
+	p := m.Presence()
+	if occupied {
+		p.Arrive(room)
+	} else {
+		p.Leave(room)
+	}
+}
@@ -0,0 +1,240 @@
+package yeelight
+
+import (
+	"net"
+	"time"
+)
+
+// config holds the tunables shared by a Manager and its lights
+type config struct {
+	readerSize   int
+	historyLen   int
+	stateHistory int
+	eventQueue   int
+	queueSize    int
+	queueTTL     time.Duration
+	reconnect    ReconnectPolicy
+
+	propCacheTTL time.Duration
+	callDeadline time.Duration
+	logger       Logger
+	events       EventHandler
+	discoverer   Discoverer
+
+	connTimeout    time.Duration
+	refreshPeriod  time.Duration
+	refreshJitter  float64
+	refreshMin     time.Duration
+	commandTimeout time.Duration
+	mcastAddr      string
+
+	keepAlive       time.Duration
+	livenessPeriod  time.Duration
+	livenessTimeout time.Duration
+
+	writeTimeout time.Duration
+	readTimeout  time.Duration
+
+	notifyQueue int
+	overflow    OverflowPolicy
+
+	transport func() Transport
+	recorder  *frameLog
+	dialer    *net.Dialer
+	bindAddr  string
+	retry     RetryPolicy
+	clock     Clock
+	tracer    Tracer
+	autoMusic int
+
+	gamma       float64
+	brightFloor int
+}
+
+func defaultConfig() config {
+	return config{
+		readerSize: 4096,
+		historyLen: 16,
+		eventQueue: 0,
+		reconnect:  DefaultReconnectPolicy,
+
+		propCacheTTL: time.Second,
+		callDeadline: 30 * time.Second,
+
+		connTimeout:    connTimeout,
+		refreshPeriod:  refreshPeriod,
+		refreshJitter:  0.1,
+		commandTimeout: time.Duration(commandTimeout) * time.Second,
+		mcastAddr:      mcastAddress,
+
+		keepAlive:       30 * time.Second,
+		livenessPeriod:  30 * time.Second,
+		livenessTimeout: 5 * time.Second,
+
+		writeTimeout: 5 * time.Second,
+
+		notifyQueue: 64,
+		overflow:    OverflowBlock,
+
+		clock: systemClock{},
+	}
+}
+
+// Option configures a Manager or a Light
+type Option func(*config)
+
+// WithReaderBufferSize sets the size in bytes of the buffer
+// used to read light's messages
+func WithReaderBufferSize(n int) Option {
+	return func(c *config) {
+		if n >= 16 {
+			c.readerSize = n
+		}
+	}
+}
+
+// WithHistoryLen sets how many transitions are kept in light's history
+func WithHistoryLen(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.historyLen = n
+		}
+	}
+}
+
+// WithEventQueueLen sets how many results and notifications are
+// queued in the Manager before lights' listeners block
+func WithEventQueueLen(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.eventQueue = n
+		}
+	}
+}
+
+// WithOfflineQueue buffers up to size state-changing commands while
+// the light is disconnected, they are sent in order on reconnection
+// unless older than ttl, a zero ttl keeps them until sent.
+// A zero size disables the queue
+func WithOfflineQueue(size int, ttl time.Duration) Option {
+	return func(c *config) {
+		if size >= 0 {
+			c.queueSize = size
+			c.queueTTL = ttl
+		}
+	}
+}
+
+// WithConnectTimeout sets how long connecting to a light may take
+func WithConnectTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.connTimeout = d
+		}
+	}
+}
+
+// WithRefreshPeriod sets how often light's state is refreshed
+// when nothing is received from it
+func WithRefreshPeriod(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.refreshPeriod = d
+		}
+	}
+}
+
+// WithRefreshJitter randomizes each refresh period by this
+// fraction (0-1) so lights don't refresh all at once
+func WithRefreshJitter(j float64) Option {
+	return func(c *config) {
+		if j >= 0 && j <= 1 {
+			c.refreshJitter = j
+		}
+	}
+}
+
+// WithAdaptiveRefresh refreshes quiet lights more often, halving
+// the period on each refresh without notifications down to min.
+// A zero min disables it
+func WithAdaptiveRefresh(min time.Duration) Option {
+	return func(c *config) {
+		c.refreshMin = min
+	}
+}
+
+// WithCommandTimeout sets how long the package waits for
+// the results of the commands it sends on its own
+func WithCommandTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.commandTimeout = d
+		}
+	}
+}
+
+// WithMulticastAddress sets the SSDP group and port used
+// by a Manager to find lights
+func WithMulticastAddress(addr string) Option {
+	return func(c *config) {
+		if addr != "" {
+			c.mcastAddr = addr
+		}
+	}
+}
+
+// WithWriteTimeout sets how long writing a command may block,
+// a zero timeout waits forever
+func WithWriteTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.writeTimeout = d
+	}
+}
+
+// WithReadTimeout sets how long Message waits for data, a zero timeout
+// waits forever. Idle lights only talk on the periodic refresh so it
+// should be longer than the refresh period
+func WithReadTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.readTimeout = d
+	}
+}
+
+// WithDialer sets the dialer connecting to lights, its zero
+// Timeout and KeepAlive are taken from the other options
+func WithDialer(d *net.Dialer) Option {
+	return func(c *config) {
+		c.dialer = d
+	}
+}
+
+// WithLocalAddr sets the local IP, or IP:port, connections to
+// lights are made from, for hosts with several interfaces
+func WithLocalAddr(addr string) Option {
+	return func(c *config) {
+		c.bindAddr = addr
+	}
+}
+
+// WithTimeout sets the connect, write and command timeouts at once
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.connTimeout = d
+			c.writeTimeout = d
+			c.commandTimeout = d
+		}
+	}
+}
+
+// Configure applies opts to light, buffer sizes take effect
+// on the next connection. They are kept over the defaults of
+// the Manager the light is added to
+func (l *Light) Configure(opts ...Option) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.opts = append(l.opts, opts...)
+	for _, o := range opts {
+		o(&l.cfg)
+	}
+}
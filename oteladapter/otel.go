@@ -0,0 +1,62 @@
+// Package oteladapter implements yeelight.Tracer with OpenTelemetry
+package oteladapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulento/yeelight"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracer struct {
+	t trace.Tracer
+}
+
+// New returns a yeelight.Tracer starting spans with t,
+// usually otel.Tracer("github.com/pulento/yeelight")
+func New(t trace.Tracer) yeelight.Tracer {
+	return tracer{t}
+}
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...yeelight.Attr) (context.Context, yeelight.Span) {
+	ctx, sp := t.t.Start(ctx, name, trace.WithAttributes(convert(attrs)...))
+	return ctx, span{sp}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttrs(attrs ...yeelight.Attr) { s.s.SetAttributes(convert(attrs)...) }
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() { s.s.End() }
+
+// convert maps attributes to OpenTelemetry's typed ones
+func convert(attrs []yeelight.Attr) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs[i] = attribute.String(a.Key, v)
+		case int:
+			kvs[i] = attribute.Int(a.Key, v)
+		case int64:
+			kvs[i] = attribute.Int64(a.Key, v)
+		case float64:
+			kvs[i] = attribute.Float64(a.Key, v)
+		case bool:
+			kvs[i] = attribute.Bool(a.Key, v)
+		default:
+			kvs[i] = attribute.String(a.Key, fmt.Sprint(v))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,87 @@
+package yeelight
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PropsUpdate is the payload of a props notification,
+// properties not notified are nil
+type PropsUpdate struct {
+	Power     *string `json:"power,omitempty"`
+	Bright    *int    `json:"bright,omitempty"`
+	ColorMode *int    `json:"color_mode,omitempty"`
+	CT        *int    `json:"ct,omitempty"`
+	RGB       *int    `json:"rgb,omitempty"`
+	Hue       *int    `json:"hue,omitempty"`
+	Sat       *int    `json:"sat,omitempty"`
+	Name      *string `json:"name,omitempty"`
+}
+
+// Props decodes a props notification, it returns false for
+// other notifications
+func (n *Notification) Props() (*PropsUpdate, bool) {
+	if n.Method != "props" {
+		return nil, false
+	}
+	p := &PropsUpdate{}
+	ints := map[string]**int{
+		"bright":     &p.Bright,
+		"color_mode": &p.ColorMode,
+		"ct":         &p.CT,
+		"rgb":        &p.RGB,
+		"hue":        &p.Hue,
+		"sat":        &p.Sat,
+	}
+	for k, dst := range ints {
+		if i, ok := paramInt(n.Params[k]); ok {
+			*dst = &i
+		}
+	}
+	if s, ok := n.Params["power"].(string); ok {
+		p.Power = &s
+	}
+	if s, ok := n.Params["name"].(string); ok {
+		p.Name = &s
+	}
+	return p, true
+}
+
+// paramInt returns v as an int, lights send numbers but
+// some firmware quote them
+func paramInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// OK returns true if the light acknowledged the command
+func (r *Result) OK() bool {
+	return r.Error == nil && len(r.Result) == 1 && r.Result[0] == "ok"
+}
+
+// AsStrings returns the values of the result formatted as strings
+func (r *Result) AsStrings() []string {
+	s := make([]string, len(r.Result))
+	for i, v := range r.Result {
+		s[i] = propString(v)
+	}
+	return s
+}
+
+// AsInt returns the i-th value of the result as an int
+func (r *Result) AsInt(i int) (int, error) {
+	if i < 0 || i >= len(r.Result) {
+		return 0, fmt.Errorf("%w: no value %d in result %d", errInvalidResult, i, r.ID)
+	}
+	n, ok := paramInt(r.Result[i])
+	if !ok {
+		return 0, fmt.Errorf("%w: value %d of result %d is %v", errInvalidResult, i, r.ID, r.Result[i])
+	}
+	return n, nil
+}
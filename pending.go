@@ -0,0 +1,143 @@
+package yeelight
+
+import (
+	"strings"
+	"time"
+)
+
+// Code of the error completing calls without a result in time
+const errCodeTimeout = -10000
+
+// call is the future of a command waiting for its result
+type call struct {
+	cmd      *Command
+	result   chan *Result
+	sent     time.Time
+	deadline time.Time
+}
+
+// WithCallDeadline sets how long a command waits for its result
+// before it is expired and completed with a timeout error
+func WithCallDeadline(d time.Duration) Option {
+	return func(c *config) {
+		if d > 0 {
+			c.callDeadline = d
+		}
+	}
+}
+
+// track registers cmd as pending its result
+func (l *Light) track(cmd *Command) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.futures == nil {
+		l.futures = make(map[int32]*call, 16)
+	}
+	l.Calls[cmd.ID] = cmd
+	now := l.now()
+	l.futures[cmd.ID] = &call{
+		cmd:      cmd,
+		result:   make(chan *Result, 1),
+		sent:     now,
+		deadline: now.Add(l.cfg.callDeadline),
+	}
+}
+
+// untrack forgets the pending command id
+func (l *Light) untrack(id int32) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.Calls, id)
+	delete(l.futures, id)
+}
+
+// complete removes the pending command of r and hands r to its
+// future recording the round trip, it returns false if no command
+// was waiting for r
+func (l *Light) complete(r *Result) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := int32(r.ID)
+	if l.Calls[id] == nil {
+		return false
+	}
+	delete(l.Calls, id)
+	if f := l.futures[id]; f != nil {
+		l.rtt.add(l.now().Sub(f.sent))
+		f.result <- r
+	}
+	return true
+}
+
+// Methods answered with ["ok"], besides the set_ ones
+var okMethods = map[string]bool{
+	"toggle": true, "bg_toggle": true, "dev_toggle": true,
+	"start_cf": true, "bg_start_cf": true, "stop_cf": true, "bg_stop_cf": true,
+	"cron_add": true, "cron_del": true,
+}
+
+// resultFits returns false if r can't answer cmd, like property
+// values answering set_power, errors fit any command
+func resultFits(cmd *Command, r *Result) bool {
+	if r.Error != nil {
+		return true
+	}
+	method := strings.TrimPrefix(cmd.Method, "bg_")
+	switch {
+	case cmd.Method == "get_prop":
+		return len(r.Result) == len(cmd.Params)
+	case strings.HasPrefix(method, "set_") || strings.HasPrefix(method, "adjust_") || okMethods[cmd.Method]:
+		return len(r.Result) == 1 && r.Result[0] == "ok"
+	}
+	return true
+}
+
+// future returns the future of command id or nil if unknown
+func (l *Light) future(id int32) *call {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.futures[id]
+}
+
+// expireCalls completes the calls past their deadline with a
+// timeout error and reclaims the futures nobody waited for
+func (l *Light) expireCalls() {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.frozen {
+		return
+	}
+	for id, f := range l.futures {
+		if now.Before(f.deadline) {
+			continue
+		}
+		if l.Calls[id] != nil {
+			delete(l.Calls, id)
+			f.result <- l.timeoutResult(id)
+		}
+		delete(l.futures, id)
+	}
+}
+
+// drainCalls completes every pending call with a timeout error
+func (l *Light) drainCalls() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, f := range l.futures {
+		if l.Calls[id] != nil {
+			delete(l.Calls, id)
+			f.result <- l.timeoutResult(id)
+		}
+		delete(l.futures, id)
+	}
+}
+
+// timeoutResult is the result of command id when none arrived
+func (l *Light) timeoutResult(id int32) *Result {
+	return &Result{
+		DevID: l.ID,
+		ID:    int(id),
+		Error: &Error{Code: errCodeTimeout, Message: "request timed out"},
+	}
+}
@@ -0,0 +1,67 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+var powerNames = map[PowerState]string{
+	OFF:     "off",
+	ON:      "on",
+	UNKNOWN: "unknown",
+}
+
+// ParsePowerState returns the power state of a power property
+// value, anything but "on" and "off" is UNKNOWN
+func ParsePowerState(s string) PowerState {
+	switch s {
+	case "on":
+		return ON
+	case "off":
+		return OFF
+	}
+	return UNKNOWN
+}
+
+// IsOn returns true if the light is on
+func (p PowerState) IsOn() bool {
+	return p == ON
+}
+
+// String returns the power as lights report it
+func (p PowerState) String() string {
+	if n, ok := powerNames[p]; ok {
+		return n
+	}
+	return fmt.Sprintf("power(%d)", int(p))
+}
+
+// MarshalJSON encodes the power as its name
+func (p PowerState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// MarshalText encodes the power as its name, for map keys and text formats
+func (p PowerState) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON decodes a power from its name
+func (p *PowerState) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return p.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText decodes a power from its name
+func (p *PowerState) UnmarshalText(text []byte) error {
+	*p = ParsePowerState(string(text))
+	return nil
+}
+
+// PowerState returns light's power, Power keeps the raw value
+func (l *Light) PowerState() PowerState {
+	return ParsePowerState(l.Power)
+}
@@ -0,0 +1,105 @@
+package yeelight
+
+import (
+	"sync"
+	"time"
+)
+
+// PresenceEvent is an arrival or departure reported by an
+// external presence detection system
+type PresenceEvent struct {
+	Zone    string    `json:"zone"`
+	Arrived bool      `json:"arrived"`
+	At      time.Time `json:"at"`
+}
+
+// Presence dispatches presence events fed by external systems
+// to the behaviors registered for each zone
+type Presence struct {
+	mu       sync.Mutex
+	handlers map[string][]func(PresenceEvent)
+	present  map[string]bool
+	clock    Clock
+	rooms    map[string]*room
+	policies map[string]IdlePolicy
+}
+
+// NewPresence returns a Presence without behaviors
+func NewPresence() *Presence {
+	return &Presence{
+		handlers: make(map[string][]func(PresenceEvent)),
+		present:  make(map[string]bool),
+		clock:    systemClock{},
+		rooms:    make(map[string]*room),
+		policies: make(map[string]IdlePolicy),
+	}
+}
+
+// Arrive reports someone arrived to zone
+func (p *Presence) Arrive(zone string) {
+	p.dispatch(PresenceEvent{Zone: zone, Arrived: true, At: p.clock.Now()})
+}
+
+// Leave reports everybody left zone
+func (p *Presence) Leave(zone string) {
+	p.dispatch(PresenceEvent{Zone: zone, Arrived: false, At: p.clock.Now()})
+}
+
+// Present returns true if zone is occupied
+func (p *Presence) Present(zone string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.present[zone]
+}
+
+// On registers f for the events of zone, an empty zone gets all events
+func (p *Presence) On(zone string, f func(PresenceEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[zone] = append(p.handlers[zone], f)
+}
+
+// OnArrive turns lights on at bright when someone arrives to zone
+// and when returns true for the arrival time, a nil when always does
+func (p *Presence) OnArrive(zone string, when func(time.Time) bool, lights []*Light, bright int) {
+	p.On(zone, func(e PresenceEvent) {
+		if !e.Arrived || (when != nil && !when(e.At)) {
+			return
+		}
+		for _, l := range lights {
+			if _, err := l.SetPower(true, Sudden); err != nil {
+				l.log().WithField("error", err).Errorf("Presence cannot turn on")
+				continue
+			}
+			l.SetBrightness(bright, Sudden)
+		}
+	})
+}
+
+// OnLeave turns lights off when zone is left
+func (p *Presence) OnLeave(zone string, lights []*Light) {
+	p.On(zone, func(e PresenceEvent) {
+		if e.Arrived {
+			return
+		}
+		for _, l := range lights {
+			if _, err := l.SetPower(false, Sudden); err != nil {
+				l.log().WithField("error", err).Errorf("Presence cannot turn off")
+			}
+		}
+	})
+}
+
+func (p *Presence) dispatch(e PresenceEvent) {
+	p.mu.Lock()
+	p.present[e.Zone] = e.Arrived
+	var handlers []func(PresenceEvent)
+	handlers = append(handlers, p.handlers[e.Zone]...)
+	if e.Zone != "" {
+		handlers = append(handlers, p.handlers[""]...)
+	}
+	p.mu.Unlock()
+	for _, f := range handlers {
+		f(e)
+	}
+}
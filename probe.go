@@ -0,0 +1,48 @@
+package yeelight
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Port where lights answer SSDP searches
+const ssdpPort = "1982"
+
+var errNoResponse = errors.New("Light did not respond")
+
+// Probe sends a unicast M-SEARCH to the light at addr, an IP,
+// returning the light built from its answer. Without answer it
+// falls back to NewLight on the control port. It works on networks
+// where multicast is unreliable
+func Probe(addr string) (*Light, error) {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	light, err := probeSSDP(net.JoinHostPort(host, ssdpPort))
+	if err == nil {
+		return light, nil
+	}
+	log().WithField("address", host).Debugf("No SSDP answer, probing control port: %s", err)
+	return NewLight(host)
+}
+
+func probeSSDP(addr string) (*Light, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connTimeout)
+	defer cancel()
+	headers, err := goSSDP{}.SearchStream(ctx, addr, searchType, "")
+	if err != nil {
+		return nil, err
+	}
+	for h := range headers {
+		light, err := Parse(h)
+		if err != nil {
+			log().Errorf("Invalid response from %s: %s", addr, err)
+			continue
+		}
+		light.setStatus(SSDP)
+		return light, nil
+	}
+	return nil, errNoResponse
+}
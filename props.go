@@ -0,0 +1,116 @@
+package yeelight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Properties documented by the protocol, lights answer ""
+// for the ones their model does not have
+var allProps = []string{
+	"power", "bright", "ct", "rgb", "hue", "sat", "color_mode",
+	"flowing", "delayoff", "flow_params", "music_on", "name",
+	"bg_power", "bg_flowing", "bg_flow_params", "bg_ct", "bg_lmode",
+	"bg_bright", "bg_rgb", "bg_hue", "bg_sat", "nl_br", "active_mode",
+	"save_state",
+}
+
+// propFields maps property names to the fields holding them
+func (l *Light) propFields() (map[string]*string, map[string]*int) {
+	strs := map[string]*string{
+		"name":           &l.Name,
+		"id":             &l.ID,
+		"model":          &l.Model,
+		"power":          &l.Power,
+		"cache-control":  &l.CacheControl,
+		"flow_params":    &l.FlowParams,
+		"bg_power":       &l.BgPower,
+		"bg_flow_params": &l.BgFlowParams,
+	}
+	ints := map[string]*int{
+		"fw_ver":      &l.FW,
+		"bright":      &l.Bright,
+		"color_mode":  &l.ColorMode,
+		"ct":          &l.CT,
+		"rgb":         &l.RGB,
+		"hue":         &l.Hue,
+		"sat":         &l.Sat,
+		"flowing":     &l.flowing,
+		"delayoff":    &l.DelayOff,
+		"music_on":    &l.MusicOn,
+		"save_state":  &l.SaveState,
+		"active_mode": &l.ActiveMode,
+		"nl_br":       &l.NlBr,
+		"bg_flowing":  &l.BgFlowing,
+		"bg_ct":       &l.BgCT,
+		"bg_lmode":    &l.BgLMode,
+		"bg_bright":   &l.BgBright,
+		"bg_rgb":      &l.BgRGB,
+		"bg_hue":      &l.BgHue,
+		"bg_sat":      &l.BgSat,
+	}
+	return strs, ints
+}
+
+// Refresh asks the light for all documented properties updating
+// light with them, changes are notified as with notifications.
+// It needs the light to be listening to get the result
+func (l *Light) Refresh(ctx context.Context) error {
+	params := make([]interface{}, len(allProps))
+	for i, p := range allProps {
+		params[i] = p
+	}
+	id, err := l.GetProp(params...)
+	if err != nil {
+		return err
+	}
+	r, err := l.WaitResultCtx(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if len(r.Result) != len(allProps) {
+		return fmt.Errorf("%w: %d values for %d properties", errInvalidResult, len(r.Result), len(allProps))
+	}
+	vals := make(map[string]interface{}, len(allProps))
+	for i, p := range allProps {
+		vals[p] = r.Result[i]
+	}
+	l.processNotification(&Notification{DevID: l.ID, Method: "props", Params: vals})
+	return nil
+}
+
+// WaitResultCtx waits the result of request id until ctx is done.
+// ErrTimeout is returned when ctx's deadline passes first, along
+// with ctx's error, and ErrMismatchedResult with a result not fitting
+// the request's method, like property values answering set_power
+func (l *Light) WaitResultCtx(ctx context.Context, id int32) (*Result, error) {
+	f := l.future(id)
+	if f == nil {
+		return nil, fmt.Errorf("%w: unknown request %d", errInvalidParam, id)
+	}
+	ctx, sp := l.startSpan(ctx, "yeelight.wait", l.requestAttrs(id)...)
+	defer sp.End()
+	select {
+	case r := <-f.result:
+		l.untrack(id)
+		if r.Error == nil || r.Error.Code != errCodeTimeout {
+			l.setStatus(ONLINE)
+		}
+		sp.SetAttrs(resultAttrs(r)...)
+		if !resultFits(f.cmd, r) {
+			return r, fmt.Errorf("%w: %v answering %s", ErrMismatchedResult, r.Result, f.cmd.Method)
+		}
+		return r, nil
+	case <-ctx.Done():
+		err := ctx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: request %d: %w", ErrTimeout, id, err)
+		}
+		sp.RecordError(err)
+		return nil, err
+	}
+}
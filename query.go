@@ -0,0 +1,95 @@
+package yeelight
+
+import "strings"
+
+// Filter selects lights in a Query
+type Filter func(l *Light) bool
+
+// Query returns the lights matching every filter sorted by ID
+func (ls *Lights) Query(filters ...Filter) []*Light {
+	var lights []*Light
+	for _, l := range ls.list() {
+		if And(filters...)(l) {
+			lights = append(lights, l)
+		}
+	}
+	return lights
+}
+
+// And matches lights matching every filter
+func And(filters ...Filter) Filter {
+	return func(l *Light) bool {
+		for _, f := range filters {
+			if !f(l) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches lights matching some filter
+func Any(filters ...Filter) Filter {
+	return func(l *Light) bool {
+		for _, f := range filters {
+			if f(l) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches lights not matching f
+func Not(f Filter) Filter {
+	return func(l *Light) bool {
+		return !f(l)
+	}
+}
+
+// Powered matches lights turned on
+func Powered() Filter {
+	return func(l *Light) bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.PowerState().IsOn()
+	}
+}
+
+// Reachable matches lights connected and answering
+func Reachable() Filter {
+	return func(l *Light) bool {
+		return l.getStatus() == ONLINE
+	}
+}
+
+// ModelIs matches lights of model or its revisions, Model being
+// the model type. "color" matches "color", "color1", "color2"...
+func ModelIs(model string) Filter {
+	return func(l *Light) bool {
+		rev := strings.TrimPrefix(l.Model, model)
+		return len(rev) < len(l.Model) && strings.Trim(rev, "0123456789") == ""
+	}
+}
+
+// Class matches lights of class
+func Class(class DeviceClass) Filter {
+	return func(l *Light) bool {
+		return ModelClass(l.Model) == class
+	}
+}
+
+// NameContains matches lights whose name contains s ignoring case
+func NameContains(s string) Filter {
+	s = strings.ToLower(s)
+	return func(l *Light) bool {
+		return strings.Contains(strings.ToLower(l.Name), s)
+	}
+}
+
+// Supports matches lights with capability c
+func Supports(c Capability) Filter {
+	return func(l *Light) bool {
+		return l.Supports(c)
+	}
+}
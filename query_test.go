@@ -0,0 +1,64 @@
+package yeelight_test
+
+import (
+	"testing"
+
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/yeelighttest"
+)
+
+func TestQueryFilters(t *testing.T) {
+	ls := yeelight.NewLights()
+	for _, b := range []*yeelighttest.Bulb{
+		bulb(t, "0x01", "color", "Desk lamp", "on", yeelighttest.DefaultSupport),
+		bulb(t, "0x02", "stripe", "TV strip", "off", yeelighttest.DefaultSupport),
+		bulb(t, "0x03", "ceiling4", "Ceiling", "on", []string{"get_prop", "set_power", "set_bright"}),
+	} {
+		l, err := b.Light()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ls.LoadOrStore(l)
+	}
+
+	tests := []struct {
+		name    string
+		filters []yeelight.Filter
+		want    []string
+	}{
+		{"none", nil, []string{"0x01", "0x02", "0x03"}},
+		{"model", []yeelight.Filter{yeelight.ModelIs("color")}, []string{"0x01"}},
+		{"model revision", []yeelight.Filter{yeelight.ModelIs("ceiling")}, []string{"0x03"}},
+		{"every filter", []yeelight.Filter{yeelight.Powered(), yeelight.Supports(yeelight.CapSetRGB)}, []string{"0x01"}},
+		{"and not", []yeelight.Filter{yeelight.And(yeelight.Powered(), yeelight.Not(yeelight.Supports(yeelight.CapSetRGB)))}, []string{"0x03"}},
+		{"any", []yeelight.Filter{yeelight.Any(yeelight.NameContains("STRIP"), yeelight.ModelIs("ceiling"))}, []string{"0x02", "0x03"}},
+		{"any of none", []yeelight.Filter{yeelight.Any()}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, l := range ls.Query(tt.filters...) {
+			got = append(got, l.ID)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// bulb returns a started Bulb closed at the end of t
+func bulb(t *testing.T, id, model, name, power string, support []string) *yeelighttest.Bulb {
+	b := yeelighttest.NewUnstartedBulb()
+	b.ID, b.Model, b.Support = id, model, support
+	b.Start()
+	t.Cleanup(b.Close)
+	b.SetProp("name", name)
+	b.SetProp("power", power)
+	return b
+}
@@ -0,0 +1,60 @@
+package yeelight
+
+import "time"
+
+// queuedCommand is a command waiting for the light to reconnect
+type queuedCommand struct {
+	cmd     *Command
+	expires time.Time
+}
+
+// queryCommands do not change light's state so they are never queued
+var queryCommands = map[string]bool{
+	"get_prop": true,
+	"cron_get": true,
+}
+
+// enqueue queues cmd for sending on reconnection returning false
+// if the queue is disabled or cmd does not change light's state
+func (l *Light) enqueue(cmd *Command) bool {
+	if queryCommands[cmd.Method] {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.queueSize == 0 {
+		return false
+	}
+	if len(l.queue) >= l.cfg.queueSize {
+		l.log().WithField("method", l.queue[0].cmd.Method).Warnf("Offline queue full, dropping oldest command")
+		l.queue = l.queue[1:]
+	}
+	l.queue = append(l.queue, queuedCommand{cmd: cmd, expires: l.now().Add(l.cfg.queueTTL)})
+	return true
+}
+
+// flushQueue sends the queued commands in order discarding expired
+// ones, on failure the unsent commands are kept for the next attempt
+func (l *Light) flushQueue() {
+	l.mu.Lock()
+	if l.frozen {
+		l.mu.Unlock()
+		return
+	}
+	q := l.queue
+	l.queue = nil
+	l.mu.Unlock()
+
+	for i, qc := range q {
+		if l.cfg.queueTTL > 0 && l.now().After(qc.expires) {
+			l.log().WithField("method", qc.cmd.Method).Debugf("Dropping expired queued command")
+			continue
+		}
+		if err := l.write(qc.cmd); err != nil {
+			l.mu.Lock()
+			l.queue = append(q[i:], l.queue...)
+			l.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package yeelight
+
+import "sync"
+
+// Quirk works around a firmware bug of the lights matching
+// Model and the FW range, only its non-nil parts apply
+type Quirk struct {
+	// Model matched, empty matches every model
+	Model string
+	// MinFW and MaxFW bound the firmware matched, zero is unbounded
+	MinFW int
+	MaxFW int
+	// Support renames methods in the announced support list
+	Support map[string]string
+	// Rewrite changes commands before they are sent
+	Rewrite func(cmd *Command)
+	// Adjust changes the model description, like parameter ranges
+	Adjust func(m *Model)
+}
+
+var (
+	quirksMu sync.RWMutex
+	quirks   = []*Quirk{
+		// Buggy FW announces set instead of set_name
+		{Support: map[string]string{"set": "set_name"}},
+	}
+)
+
+// RegisterQuirk adds a quirk applied to lights found afterwards,
+// quirks apply in registration order
+func RegisterQuirk(q *Quirk) {
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	quirks = append(quirks, q)
+}
+
+// matches returns true if q applies to model at firmware fw
+func (q *Quirk) matches(model string, fw int) bool {
+	if q.Model != "" && q.Model != model {
+		return false
+	}
+	if q.MinFW != 0 && fw < q.MinFW {
+		return false
+	}
+	return q.MaxFW == 0 || fw <= q.MaxFW
+}
+
+// quirksFor returns the quirks applying to light
+func (l *Light) quirksFor() []*Quirk {
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+	var qs []*Quirk
+	for _, q := range quirks {
+		if q.matches(l.Model, l.FW) {
+			qs = append(qs, q)
+		}
+	}
+	return qs
+}
+
+// patchSupport applies the support renames of light's quirks
+func (l *Light) patchSupport() {
+	for _, q := range l.quirksFor() {
+		for from, to := range q.Support {
+			if l.Support[from] {
+				delete(l.Support, from)
+				l.Support[to] = true
+			}
+		}
+	}
+}
+
+// rewrite applies the command rewrites of light's quirks to cmd
+func (l *Light) rewrite(cmd *Command) {
+	for _, q := range l.quirksFor() {
+		if q.Rewrite != nil {
+			q.Rewrite(cmd)
+		}
+	}
+}
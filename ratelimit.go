@@ -0,0 +1,90 @@
+package yeelight
+
+import (
+	"sync"
+	"time"
+)
+
+// Lights reject commands above this many per minute and client
+const commandQuota = 60
+
+// Commands that can be sent back to back before spacing starts
+const rateBurst = 10
+
+// rateLimiter is a token bucket spacing commands to a light, the refill
+// rate leaves room for the burst so no 60s window exceeds the quota
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	burst  float64
+	rate   float64
+	last   time.Time
+}
+
+func newRateLimiter(perMinute, burst int, now time.Time) *rateLimiter {
+	if burst >= perMinute {
+		burst = perMinute / 2
+	}
+	return &rateLimiter{
+		tokens: float64(burst),
+		burst:  float64(burst),
+		rate:   float64(perMinute-burst) / 60,
+		last:   now,
+	}
+}
+
+// reserve takes a token returning how long the caller
+// must wait before the token is valid
+func (r *rateLimiter) reserve(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / r.rate * float64(time.Second))
+}
+
+// SetRateLimit limits commands sent to light to perMinute, spacing
+// them instead of letting the light reject them, zero disables it.
+// With autoMusic commands switch to music mode instead of waiting
+func (l *Light) SetRateLimit(perMinute int, autoMusic bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if perMinute <= 0 {
+		l.limiter = nil
+	} else {
+		l.limiter = newRateLimiter(perMinute, rateBurst, l.now())
+	}
+	l.autoMusic = autoMusic
+}
+
+// throttle waits until comm can be sent without exceeding the quota
+func (l *Light) throttle(comm string) {
+	l.mu.Lock()
+	limiter, autoMusic, music := l.limiter, l.autoMusic, l.music
+	l.mu.Unlock()
+	if (music != nil && viaMusic(comm)) || comm == "set_music" {
+		return
+	}
+	if music == nil && viaMusic(comm) && l.nearQuota() && l.autoStartMusic() {
+		return
+	}
+	if limiter == nil {
+		return
+	}
+	d := limiter.reserve(l.now())
+	if d <= 0 {
+		return
+	}
+	// Music mode only spares the quota of state changes
+	if autoMusic && music == nil && viaMusic(comm) && l.Support["set_music"] && l.autoStartMusic() {
+		return
+	}
+	<-l.clock().After(d)
+}
@@ -0,0 +1,102 @@
+package yeelight
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Floor of InitialDelay so a zero policy does not spin
+const minReconnectDelay = 100 * time.Millisecond
+
+// ReconnectPolicy controls how a listening light reconnects
+// after losing its connection
+type ReconnectPolicy struct {
+	// InitialDelay is the wait after the first failed attempt,
+	// doubled on each following failure up to MaxDelay if set
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Jitter randomizes each delay by this fraction (0-1)
+	Jitter float64
+	// MaxAttempts gives up after this many attempts, zero retries forever
+	MaxAttempts int
+}
+
+// DefaultReconnectPolicy is used by lights without a configured policy
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     time.Minute,
+	Jitter:       0.2,
+	MaxAttempts:  0,
+}
+
+// WithReconnectPolicy sets the policy used when connection is lost
+func WithReconnectPolicy(p ReconnectPolicy) Option {
+	return func(c *config) {
+		c.reconnect = p
+	}
+}
+
+// delay returns the wait before attempt, attempts start at 0
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	if attempt == 0 {
+		return 0
+	}
+	d := p.InitialDelay
+	if d < minReconnectDelay {
+		d = minReconnectDelay
+	}
+	// Doubling stops once capped or before the jitter overflows
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay) && d <= math.MaxInt64/4; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// reconnect tries to connect the light again following its policy,
+// it returns false if it gave up or done was signaled
+func (l *Light) reconnect(done <-chan struct{}) bool {
+	p := l.cfg.reconnect
+	lightLog := l.log()
+	for attempt := 0; p.MaxAttempts == 0 || attempt < p.MaxAttempts; attempt++ {
+		wait := p.delay(attempt)
+		if wait > 0 {
+			lightLog.WithField("attempt", attempt).Debugf("Reconnecting in %s", wait)
+		}
+		select {
+		case <-done:
+			return false
+		case <-l.clock().After(wait):
+		}
+		err := l.Connect()
+		if err == nil {
+			lightLog.Infof("Reconnected")
+			l.counters.reconnects.Add(1)
+			l.emit(Event{Kind: EventReconnect, Address: l.Address})
+			return true
+		}
+		lightLog.WithField("error", err).Errorf("Error reconnecting")
+	}
+	lightLog.Errorf("Giving up reconnecting")
+	return false
+}
+
+// resync refreshes the whole state of light after a reconnect, as
+// notifications sent while disconnected were missed
+func (l *Light) resync() {
+	l.setStatus(UPDATING)
+	ctx, cancel := context.WithTimeout(context.Background(), l.cfg.commandTimeout)
+	defer cancel()
+	if err := l.Refresh(ctx); err != nil {
+		l.log().WithField("error", err).Warnf("Error resyncing state")
+		return
+	}
+	l.emit(Event{Kind: EventResync})
+}
@@ -0,0 +1,74 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Directions of a recorded Frame
+const (
+	FrameSent     = "send"
+	FrameReceived = "recv"
+)
+
+// Frame is a message exchanged with a light as recorded by WithRecorder
+type Frame struct {
+	At   time.Time       `json:"at"`
+	Addr string          `json:"addr"`
+	Dir  string          `json:"dir"`
+	Data json.RawMessage `json:"data"`
+}
+
+// WithRecorder writes every frame exchanged with lights to w as JSON
+// lines, handy to capture quirky firmware for regression tests
+func WithRecorder(w io.Writer) Option {
+	fl := &frameLog{enc: json.NewEncoder(w)}
+	return func(c *config) {
+		c.recorder = fl
+	}
+}
+
+// frameLog serializes the frames of all lights sharing a writer
+type frameLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (fl *frameLog) write(f Frame) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.enc.Encode(f)
+}
+
+// recorder is a Transport logging the frames of the one it wraps
+type recorder struct {
+	Transport
+	log  *frameLog
+	addr string
+}
+
+// Dial implements Transport
+func (r *recorder) Dial(addr string) error {
+	r.addr = addr
+	return r.Transport.Dial(addr)
+}
+
+// Send implements Transport
+func (r *recorder) Send(b []byte) error {
+	data := make([]byte, len(b)-len(endOfCommand))
+	copy(data, b)
+	r.log.write(Frame{At: time.Now(), Addr: r.addr, Dir: FrameSent, Data: data})
+	return r.Transport.Send(b)
+}
+
+// Receive implements Transport
+func (r *recorder) Receive(v interface{}) error {
+	var raw json.RawMessage
+	if err := r.Transport.Receive(&raw); err != nil {
+		return err
+	}
+	r.log.write(Frame{At: time.Now(), Addr: r.addr, Dir: FrameReceived, Data: raw})
+	return json.Unmarshal(raw, v)
+}
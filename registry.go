@@ -0,0 +1,146 @@
+package yeelight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Registry is the lights collection as persisted by Save and Load
+type Registry = Lights
+
+// persistedLight is what Save stores of each light
+type persistedLight struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Model   string   `json:"model"`
+	FW      int      `json:"fw"`
+	Support []string `json:"support"`
+	Alias   string   `json:"alias,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+
+	Calibration *Calibration `json:"calibration,omitempty"`
+}
+
+// Save writes the identity, alias, tags and calibration of the lights to
+// path as JSON, replacing it atomically so a crash never leaves a
+// truncated file
+func (ls *Lights) Save(path string) error {
+	var list []persistedLight
+	ls.Range(func(_ string, l *Light) bool {
+		p := persistedLight{
+			ID:      l.ID,
+			Name:    l.Name,
+			Address: l.Address,
+			Model:   l.Model,
+			FW:      l.FW,
+			Alias:   ls.Alias(l.ID),
+			Tags:    ls.Tags(l.ID),
+
+			Calibration: l.Calibration(),
+		}
+		for m, ok := range l.Support {
+			if ok {
+				p.Support = append(p.Support, m)
+			}
+		}
+		sort.Strings(p.Support)
+		list = append(list, p)
+		return true
+	})
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic replaces path by data through a temporary file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load adds the lights stored in path by Save, lights already in
+// the collection are kept. Loaded lights are not connected. Entries
+// whose ID belongs to another kind of device are skipped, reported
+// in the returned error along with the lights added
+func (ls *Lights) Load(path string) ([]*Light, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list []persistedLight
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	var added []*Light
+	var errs []error
+	for _, p := range list {
+		l := newLight(p.Address)
+		l.ID = p.ID
+		l.Name = p.Name
+		l.Model = p.Model
+		l.FW = p.FW
+		for _, m := range p.Support {
+			l.Support[m] = true
+		}
+		actual, ok := ls.LoadOrStore(l)
+		if actual == nil {
+			errs = append(errs, fmt.Errorf("%w: %s", errNotALight, p.ID))
+			continue
+		}
+		if ok {
+			added = append(added, l)
+		}
+		if p.Calibration != nil {
+			if err := actual.SetCalibration(p.Calibration); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.ID, err))
+			}
+		}
+		if p.Alias != "" {
+			if err := ls.SetAlias(p.ID, p.Alias); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.ID, err))
+			}
+		}
+		ls.Tag(p.ID, p.Tags...)
+	}
+	return added, errors.Join(errs...)
+}
+
+// Save persists the lights known by the Manager to path
+func (m *Manager) Save(path string) error {
+	return m.lights.Save(path)
+}
+
+// Restore loads the lights saved in path and starts listening
+// them without waiting for discovery. The errors of the lights
+// not loaded or not reachable are returned joined, unreachable
+// lights are kept until discovered again
+func (m *Manager) Restore(path string) error {
+	added, err := m.lights.Load(path)
+	errs := []error{err}
+	for _, l := range added {
+		m.discovered(l)
+		if err := m.listen(l); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", l.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,96 @@
+package yeelight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how Call retries commands failing with
+// transient errors, like a reset connection or exceeded quota
+type RetryPolicy struct {
+	// InitialDelay is the wait before the first retry,
+	// doubled on each following one up to MaxDelay if set
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// Jitter randomizes each delay by this fraction (0-1)
+	Jitter float64
+	// MaxAttempts bounds the attempts including the first,
+	// zero or one never retries
+	MaxAttempts int
+}
+
+// RetryError is the error of a command that failed after retries
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements the error interface
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("after %d attempts: %s", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// WithRetryPolicy sets the retries of Call, no retries by default
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = p
+	}
+}
+
+// Call sends a command waiting its result until ctx is done,
+// retrying transient failures with light's retry policy. Light
+// errors are returned along with the result carrying them
+func (l *Light) Call(ctx context.Context, method string, params ...interface{}) (*Result, error) {
+	return l.CallWithRetry(ctx, l.cfg.retry, method, params...)
+}
+
+// CallWithRetry is Call with retry policy p for this call only
+func (l *Light) CallWithRetry(ctx context.Context, p RetryPolicy, method string, params ...interface{}) (*Result, error) {
+	for attempt := 1; ; attempt++ {
+		r, err := l.call(ctx, method, params...)
+		if err == nil || !transient(err) || attempt >= p.MaxAttempts {
+			if err != nil && attempt > 1 {
+				err = &RetryError{Attempts: attempt, Err: err}
+			}
+			return r, err
+		}
+		wait := ReconnectPolicy(p).delay(attempt)
+		l.log().WithFields(Fields{"attempt": attempt, "error": err}).Debugf("Retrying %s in %s", method, wait)
+		select {
+		case <-ctx.Done():
+			return r, &RetryError{Attempts: attempt, Err: ctx.Err()}
+		case <-l.clock().After(wait):
+		}
+	}
+}
+
+// call sends a command once waiting its result
+func (l *Light) call(ctx context.Context, method string, params ...interface{}) (*Result, error) {
+	id, err := l.SendCommand(method, params...)
+	if err != nil {
+		return nil, err
+	}
+	r, err := l.WaitResultCtx(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r, r.Err()
+}
+
+// transient returns true for errors worth retrying
+func transient(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, errNotConnected)
+}
@@ -0,0 +1,398 @@
+package yeelight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Routine is a declarative automation, in JSON:
+//
+//	{"name": "night", "enabled": true,
+//	 "trigger": {"at": "22:00"},
+//	 "actions": [{"group": "living-room", "do": "bright", "value": 20, "duration": "10m"}]}
+//
+// and
+//
+//	{"name": "watch", "enabled": true,
+//	 "trigger": {"offline": "0x0000000012345678"},
+//	 "actions": [{"do": "notify", "message": "hall light is offline"}]}
+//
+// Arrive and Leave triggers are fed by the Manager's Presence:
+//
+//	{"name": "welcome", "enabled": true,
+//	 "trigger": {"arrive": "hallway"},
+//	 "actions": [{"group": "hallway", "do": "bright", "value": 30}]}
+type Routine struct {
+	Name    string   `json:"name"`
+	Enabled bool     `json:"enabled"`
+	Trigger Trigger  `json:"trigger"`
+	Actions []Action `json:"actions"`
+}
+
+// Trigger is what starts a routine, only one field is set
+type Trigger struct {
+	// At is a daily time as understood by ParseSolarTime,
+	// like "22:00" or "sunset-30m"
+	At string `json:"at,omitempty"`
+	// Every is an interval like "15m"
+	Every string `json:"every,omitempty"`
+	// Offline and Online are the ID or name of a light going
+	// offline or coming back online
+	Offline string `json:"offline,omitempty"`
+	Online  string `json:"online,omitempty"`
+	// Arrive and Leave are zones reported to the Manager's Presence
+	Arrive string `json:"arrive,omitempty"`
+	Leave  string `json:"leave,omitempty"`
+}
+
+// Action is a step of a routine
+type Action struct {
+	// Lights are IDs or names of the lights acted on,
+	// along with the lights of Group
+	Lights []string `json:"lights,omitempty"`
+	Group  string   `json:"group,omitempty"`
+	// Do is one of "power", "bright", "ct", "rgb" or "notify"
+	Do string `json:"do"`
+	// Value is "on" or "off" for power and a number otherwise
+	Value interface{} `json:"value,omitempty"`
+	// Duration of the transition, like "10m"
+	Duration string `json:"duration,omitempty"`
+	// Message is sent by notify actions
+	Message string `json:"message,omitempty"`
+}
+
+// Routines runs routines with a Manager's scheduler and events
+type Routines struct {
+	m        *Manager
+	mu       sync.Mutex
+	routines map[string]*Routine
+	groups   map[string]*Group
+	// Coordinates resolve solar times in At triggers
+	Coordinates Coordinates
+	// Notify is called by notify actions, if set
+	Notify func(routine, message string)
+}
+
+// Prefix of the scheduler jobs of routines
+const routineJobPrefix = "routine:"
+
+// NewRoutines returns a Routines engine for m's lights
+func NewRoutines(m *Manager) *Routines {
+	r := &Routines{
+		m:        m,
+		routines: make(map[string]*Routine),
+		groups:   make(map[string]*Group),
+	}
+	m.AddEventHandler(EventHandlerFunc(r.handleEvent))
+	m.Presence().On("", r.handlePresence)
+	return r
+}
+
+// AddGroup makes g available to actions by its name
+func (r *Routines) AddGroup(g *Group) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groups[g.Name] = g
+}
+
+// Load replaces the routines by the JSON array in data, on error
+// the current routines are kept running. YAML definitions are
+// loaded by package routinesyaml
+func (r *Routines) Load(data []byte) error {
+	var list []Routine
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	routines := make(map[string]*Routine, len(list))
+	for i := range list {
+		rt := &list[i]
+		if err := rt.validate(); err != nil {
+			return err
+		}
+		if routines[rt.Name] != nil {
+			return fmt.Errorf("%w: duplicated routine %q", errInvalidParam, rt.Name)
+		}
+		routines[rt.Name] = rt
+	}
+	r.mu.Lock()
+	old := r.routines
+	r.routines = routines
+	r.mu.Unlock()
+	for name := range old {
+		r.m.Scheduler().Remove(routineJobPrefix + name)
+	}
+	for _, rt := range routines {
+		if rt.Enabled {
+			r.schedule(rt)
+		}
+	}
+	return nil
+}
+
+// LoadFile loads the routines stored in path, call it again to reload
+func (r *Routines) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return r.Load(data)
+}
+
+// Enable enables routine name
+func (r *Routines) Enable(name string) error {
+	return r.setEnabled(name, true)
+}
+
+// Disable disables routine name
+func (r *Routines) Disable(name string) error {
+	return r.setEnabled(name, false)
+}
+
+func (r *Routines) setEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	rt := r.routines[name]
+	if rt != nil {
+		rt.Enabled = enabled
+	}
+	r.mu.Unlock()
+	if rt == nil {
+		return fmt.Errorf("%w: unknown routine %q", errInvalidParam, name)
+	}
+	if enabled {
+		r.schedule(rt)
+	} else {
+		r.m.Scheduler().Remove(routineJobPrefix + name)
+	}
+	return nil
+}
+
+// List returns the loaded routines sorted by name
+func (r *Routines) List() []Routine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]Routine, 0, len(r.routines))
+	for _, name := range sortedKeys(r.routines) {
+		list = append(list, *r.routines[name])
+	}
+	return list
+}
+
+// validate checks rt can run
+func (rt *Routine) validate() error {
+	if rt.Name == "" {
+		return fmt.Errorf("%w: routine without name", errInvalidParam)
+	}
+	t := rt.Trigger
+	set := 0
+	for _, f := range []string{t.At, t.Every, t.Offline, t.Online, t.Arrive, t.Leave} {
+		if f != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("%w: routine %q needs exactly one trigger", errInvalidParam, rt.Name)
+	}
+	if t.Every != "" {
+		if d, err := time.ParseDuration(t.Every); err != nil || d <= 0 {
+			return fmt.Errorf("%w: routine %q interval %q", errInvalidParam, rt.Name, t.Every)
+		}
+	}
+	if t.At != "" {
+		// Sun always rises and sets on the equator, only the syntax is checked
+		if _, err := ParseSolarTime(t.At, time.Now(), Coordinates{}); err != nil {
+			return fmt.Errorf("routine %q: %w", rt.Name, err)
+		}
+	}
+	for _, a := range rt.Actions {
+		switch a.Do {
+		case "power", "bright", "ct", "rgb", "notify":
+		default:
+			return fmt.Errorf("%w: routine %q action %q", errInvalidParam, rt.Name, a.Do)
+		}
+		if a.Duration != "" {
+			if _, err := time.ParseDuration(a.Duration); err != nil {
+				return fmt.Errorf("%w: routine %q duration %q", errInvalidParam, rt.Name, a.Duration)
+			}
+		}
+	}
+	return nil
+}
+
+// schedule adds the scheduler job of a timed routine
+func (r *Routines) schedule(rt *Routine) {
+	var sched Schedule
+	switch {
+	case rt.Trigger.Every != "":
+		d, _ := time.ParseDuration(rt.Trigger.Every)
+		sched = Every(d)
+	case rt.Trigger.At != "":
+		sched = r.daily(rt.Trigger.At)
+	default:
+		return
+	}
+	name := rt.Name
+	r.m.Scheduler().Add(routineJobPrefix+name, sched, func() { r.Run(name) })
+}
+
+// daily returns the schedule of a daily time expression
+func (r *Routines) daily(expr string) Schedule {
+	return func(t time.Time) time.Time {
+		for day := 0; day < 2; day++ {
+			at, err := ParseSolarTime(expr, t.AddDate(0, 0, day), r.Coordinates)
+			if err == nil && at.After(t) {
+				return at
+			}
+		}
+		// Polar days and nights are retried tomorrow
+		return t.Add(24 * time.Hour)
+	}
+}
+
+// handleEvent runs the routines triggered by lights' status changes
+func (r *Routines) handleEvent(e Event) {
+	if e.Kind != EventStatus || e.Status.From == e.Status.To {
+		return
+	}
+	l := r.m.Get(e.DevID)
+	if l == nil {
+		return
+	}
+	var names []string
+	r.mu.Lock()
+	for name, rt := range r.routines {
+		if !rt.Enabled {
+			continue
+		}
+		t := rt.Trigger
+		switch {
+		case e.Status.To == OFFLINE && t.Offline != "" && matchLight(l, t.Offline):
+		case e.Status.From == OFFLINE && e.Status.To == ONLINE && t.Online != "" && matchLight(l, t.Online):
+		default:
+			continue
+		}
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	for _, name := range names {
+		// Events arrive on lights' goroutines which must not block
+		go r.Run(name)
+	}
+}
+
+// handlePresence runs the routines triggered by arrivals and departures
+func (r *Routines) handlePresence(e PresenceEvent) {
+	var names []string
+	r.mu.Lock()
+	for name, rt := range r.routines {
+		t := rt.Trigger
+		if rt.Enabled && e.Zone != "" && ((e.Arrived && t.Arrive == e.Zone) || (!e.Arrived && t.Leave == e.Zone)) {
+			names = append(names, name)
+		}
+	}
+	r.mu.Unlock()
+	for _, name := range names {
+		r.Run(name)
+	}
+}
+
+// matchLight returns true if ref is l's ID or name
+func matchLight(l *Light, ref string) bool {
+	return l.ID == ref || (l.Name != "" && l.Name == ref)
+}
+
+// Run runs the actions of routine name now, whatever its trigger
+func (r *Routines) Run(name string) error {
+	r.mu.Lock()
+	rt := r.routines[name]
+	r.mu.Unlock()
+	if rt == nil {
+		return fmt.Errorf("%w: unknown routine %q", errInvalidParam, name)
+	}
+	var err error
+	for _, a := range rt.Actions {
+		if aerr := r.act(rt.Name, a); aerr != nil {
+			r.m.cfg.getLogger().WithFields(Fields{"routine": rt.Name, "error": aerr}).Errorf("Routine action failed")
+			if err == nil {
+				err = aerr
+			}
+		}
+	}
+	return err
+}
+
+// act runs action a of routine name
+func (r *Routines) act(name string, a Action) error {
+	if a.Do == "notify" {
+		if r.Notify != nil {
+			r.Notify(name, a.Message)
+		}
+		return nil
+	}
+	method, params, err := a.command()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.m.cfg.commandTimeout)
+	defer cancel()
+	_, err = Broadcast(ctx, r.targets(a), 0, method, params...)
+	return err
+}
+
+// command returns the light command of a
+func (a Action) command() (string, []interface{}, error) {
+	var d time.Duration
+	if a.Duration != "" {
+		d, _ = time.ParseDuration(a.Duration)
+	}
+	effect, err := Transition(d).Params()
+	if err != nil {
+		return "", nil, err
+	}
+	if a.Do == "power" {
+		p, _ := a.Value.(string)
+		if p != "on" && p != "off" {
+			return "", nil, fmt.Errorf("%w: power %v", errInvalidParam, a.Value)
+		}
+		return "set_power", append([]interface{}{p}, effect...), nil
+	}
+	v, ok := a.Value.(float64)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s %v", errInvalidParam, a.Do, a.Value)
+	}
+	method := map[string]string{"bright": "set_bright", "ct": "set_ct_abx", "rgb": "set_rgb"}[a.Do]
+	return method, append([]interface{}{int(v)}, effect...), nil
+}
+
+// targets returns the lights acted on by a
+func (r *Routines) targets(a Action) []*Light {
+	var lights []*Light
+	seen := make(map[*Light]bool)
+	add := func(l *Light) {
+		if l != nil && !seen[l] {
+			seen[l] = true
+			lights = append(lights, l)
+		}
+	}
+	for _, ref := range a.Lights {
+		for l := range r.m.All() {
+			if matchLight(l, ref) {
+				add(l)
+			}
+		}
+	}
+	if a.Group != "" {
+		r.mu.Lock()
+		g := r.groups[a.Group]
+		r.mu.Unlock()
+		if g != nil {
+			for _, l := range g.Lights {
+				add(l)
+			}
+		}
+	}
+	return lights
+}
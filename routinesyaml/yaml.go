@@ -0,0 +1,41 @@
+// Package routinesyaml loads yeelight routines written in YAML,
+// like the JSON ones taken by yeelight.Routines:
+//
+//	# routines.yaml
+//	- name: night
+//	  enabled: true
+//	  trigger: {at: "22:00"}
+//	  actions:
+//	    - {group: living-room, do: bright, value: 20, duration: 10m}
+package routinesyaml
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pulento/yeelight"
+	"gopkg.in/yaml.v3"
+)
+
+// Load replaces the routines of r by the YAML list in data,
+// on error the current routines are kept running
+func Load(r *yeelight.Routines, data []byte) error {
+	var list []interface{}
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	j, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return r.Load(j)
+}
+
+// LoadFile loads the routines stored in path, call it again to reload
+func LoadFile(r *yeelight.Routines, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return Load(r, data)
+}
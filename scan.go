@@ -0,0 +1,96 @@
+package yeelight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// Time waited for each host to accept a connection while scanning
+var scanTimeout = 500 * time.Millisecond
+
+// Largest network Scan accepts, a /16 on IPv4
+const maxScanHosts = 1 << 16
+
+// Scan probes the control port of every host in cidr with at most
+// workers connections at a time, sending the lights found on the
+// returned channel which is closed when done. It is a fallback for
+// networks where multicast is blocked, opts are passed to NewLight
+func Scan(ctx context.Context, cidr string, workers int, opts ...Option) (<-chan *Light, error) {
+	return scan(ctx, cidr, workers, func(addr string) (*Light, error) {
+		return NewLight(addr, opts...)
+	})
+}
+
+// scan is Scan building the lights found with open
+func scan(ctx context.Context, cidr string, workers int, open func(addr string) (*Light, error)) (<-chan *Light, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidParam, err)
+	}
+	prefix = prefix.Masked()
+	if bits := prefix.Addr().BitLen() - prefix.Bits(); bits > 16 {
+		return nil, fmt.Errorf("%w: network %s larger than %d hosts", errInvalidParam, cidr, maxScanHosts)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	hosts := make(chan netip.Addr)
+	go func() {
+		defer close(hosts)
+		for a := prefix.Addr(); prefix.Contains(a); a = a.Next() {
+			select {
+			case hosts <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	lights := make(chan *Light)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for a := range hosts {
+				l := scanHost(ctx, a, open)
+				if l == nil {
+					continue
+				}
+				select {
+				case lights <- l:
+				case <-ctx.Done():
+					l.Close()
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(lights)
+	}()
+	return lights, nil
+}
+
+// scanHost returns the light at a or nil if there is none
+func scanHost(ctx context.Context, a netip.Addr, open func(string) (*Light, error)) *Light {
+	addr := net.JoinHostPort(a.String(), controlPort)
+	d := net.Dialer{Timeout: scanTimeout}
+	cn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	cn.Close()
+	l, err := open(addr)
+	if err != nil {
+		log().WithField("address", addr).Debugf("Port open but not a light: %s", err)
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,137 @@
+package yeelight
+
+import (
+	"sync"
+	"time"
+)
+
+// Schedule returns the next run of a job after t,
+// a zero time means the job won't run again
+type Schedule func(t time.Time) time.Time
+
+// Every returns a schedule running each d
+func Every(d time.Duration) Schedule {
+	return func(t time.Time) time.Time {
+		return t.Add(d)
+	}
+}
+
+// Scheduler runs jobs on the client following their schedules,
+// jobs are identified by name and run on their own goroutines.
+// Unlike the on-device cron, which only turns lights off, any
+// command can be scheduled
+type Scheduler struct {
+	clock   Clock
+	timeout time.Duration
+	logger  Logger
+	lights  *Lights
+	mu      sync.Mutex
+	jobs    map[string]chan struct{}
+	wg      sync.WaitGroup
+	specs   map[string]JobSpec
+	groups  map[string]*Group
+	path    string
+}
+
+// NewScheduler returns a Scheduler without jobs targeting lights,
+// only the clock, logger and command timeout of opts are used
+func NewScheduler(lights *Lights, opts ...Option) *Scheduler {
+	cfg := defaultConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return newScheduler(lights, &cfg)
+}
+
+func newScheduler(lights *Lights, cfg *config) *Scheduler {
+	return &Scheduler{
+		clock:   cfg.clock,
+		timeout: cfg.commandTimeout,
+		logger:  cfg.logger,
+		lights:  lights,
+		jobs:    make(map[string]chan struct{}),
+		specs:   make(map[string]JobSpec),
+		groups:  make(map[string]*Group),
+	}
+}
+
+// Add schedules f as name replacing the job with the same name
+func (s *Scheduler) Add(name string, sched Schedule, f func()) {
+	stop := make(chan struct{})
+	s.mu.Lock()
+	if old := s.jobs[name]; old != nil {
+		close(old)
+	}
+	s.jobs[name] = stop
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.run(name, sched, f, stop)
+}
+
+// Remove unschedules job name, it returns false if unknown
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.specs[name]; ok {
+		delete(s.specs, name)
+		s.save()
+	}
+	stop := s.jobs[name]
+	if stop == nil {
+		return false
+	}
+	close(stop)
+	delete(s.jobs, name)
+	return true
+}
+
+// Jobs returns the names of the scheduled jobs
+func (s *Scheduler) Jobs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.jobs)
+}
+
+// Close removes all jobs waiting for the running ones to end
+func (s *Scheduler) Close() error {
+	s.mu.Lock()
+	for name, stop := range s.jobs {
+		close(stop)
+		delete(s.jobs, name)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) run(name string, sched Schedule, f func(), stop chan struct{}) {
+	defer s.wg.Done()
+	for {
+		now := s.clock.Now()
+		next := sched(now)
+		if next.IsZero() {
+			s.mu.Lock()
+			if s.jobs[name] == stop {
+				delete(s.jobs, name)
+			}
+			s.mu.Unlock()
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-s.clock.After(next.Sub(now)):
+			f()
+		}
+	}
+}
+
+// Scheduler returns the Manager's scheduler, using its clock
+func (m *Manager) Scheduler() *Scheduler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sched == nil {
+		m.sched = newScheduler(m.lights, &m.cfg)
+	}
+	return m.sched
+}
@@ -0,0 +1,78 @@
+package yeelight
+
+import "context"
+
+// Shutdown stops listening light, leaves music mode and closes its
+// connection, pending calls are completed with a timeout error.
+// It returns ctx's error if listening did not end in time
+func (l *Light) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	ln := l.listener
+	l.listener = nil
+	music := l.music
+	l.music = nil
+	l.mu.Unlock()
+
+	var err error
+	if music != nil {
+		music.Close()
+	}
+	if ln != nil {
+		ln.Stop()
+		select {
+		case <-ln.Done():
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	} else if l.transport != nil {
+		l.Close()
+	}
+	l.drainCalls()
+	return err
+}
+
+// Close stops the SSDP monitor and shuts down every device, the events
+// channel is closed once all listeners ended. The Manager can't be
+// used afterwards. It returns ctx's error if it did not finish in time
+func (m *Manager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return errManagerClosed
+	}
+	m.closed = true
+	close(m.stop)
+	mon := m.monitor
+	m.monitor = nil
+	sched := m.sched
+	presence := m.presence
+	m.mu.Unlock()
+	m.dialing.Wait()
+
+	if mon != nil {
+		mon.Close()
+	}
+	if sched != nil {
+		sched.Close()
+	}
+	if presence != nil {
+		presence.Close()
+	}
+	var err error
+	for d := range m.Devices() {
+		if serr := m.shutdownDevice(ctx, d); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if err == nil {
+		close(m.events)
+	}
+	return err
+}
+
+// isClosed returns true once Close was called
+func (m *Manager) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
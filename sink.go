@@ -0,0 +1,227 @@
+package yeelight
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Point is a measurement of a light's state at some time
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	At          time.Time
+}
+
+// Sink stores points, usually in a time-series database
+type Sink interface {
+	Write(p Point) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(p Point) error
+
+// Write calls f(p)
+func (f SinkFunc) Write(p Point) error {
+	return f(p)
+}
+
+// lineSink writes points in InfluxDB line protocol
+type lineSink struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// LineProtocolSink returns a Sink writing points to w in InfluxDB
+// line protocol, one per line with nanosecond timestamps
+func LineProtocolSink(w io.Writer) Sink {
+	return &lineSink{w: bufio.NewWriter(w)}
+}
+
+func (s *lineSink) Write(p Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.WriteString(p.Line()); err != nil {
+		return err
+	}
+	if err := s.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringEscaper      = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+)
+
+// Line formats p in InfluxDB line protocol, tags and
+// fields are sorted by key
+func (p Point) Line() string {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(p.Measurement))
+	for _, k := range sortedKeys(p.Tags) {
+		if p.Tags[k] == "" {
+			// Empty tag values are not allowed
+			continue
+		}
+		fmt.Fprintf(&b, ",%s=%s", tagEscaper.Replace(k), tagEscaper.Replace(p.Tags[k]))
+	}
+	sep := byte(' ')
+	for _, k := range sortedKeys(p.Fields) {
+		b.WriteByte(sep)
+		sep = ','
+		b.WriteString(tagEscaper.Replace(k))
+		b.WriteByte('=')
+		switch v := p.Fields[k].(type) {
+		case int:
+			b.WriteString(strconv.Itoa(v) + "i")
+		case int64:
+			b.WriteString(strconv.FormatInt(v, 10) + "i")
+		case float64:
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			b.WriteString(strconv.FormatBool(v))
+		default:
+			b.WriteString(`"` + stringEscaper.Replace(fmt.Sprint(v)) + `"`)
+		}
+	}
+	if !p.At.IsZero() {
+		b.WriteString(" " + strconv.FormatInt(p.At.UnixNano(), 10))
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Measurement name of the points written by SinkWriter
+const sinkMeasurement = "yeelight"
+
+// SinkWriter is an EventHandler writing the state of lights to a
+// Sink on every notification and status change. Points are written
+// from its own goroutine so a slow sink never blocks lights
+type SinkWriter struct {
+	lights *Lights
+	sink   Sink
+	points chan Point
+	done   chan struct{}
+	mu     sync.Mutex
+	closed bool
+	// OnError is called with the errors writing points, if set
+	OnError func(error)
+}
+
+// NewSinkWriter returns a SinkWriter for lights queuing up to buffer
+// points, points are dropped when the queue is full. Pass it to
+// WithEventHandler and call Close when done
+func NewSinkWriter(lights *Lights, sink Sink, buffer int) *SinkWriter {
+	w := &SinkWriter{
+		lights: lights,
+		sink:   sink,
+		points: make(chan Point, buffer),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *SinkWriter) run() {
+	defer close(w.done)
+	for p := range w.points {
+		if err := w.sink.Write(p); err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	}
+}
+
+// HandleEvent implements EventHandler
+func (w *SinkWriter) HandleEvent(e Event) {
+	if e.Kind != EventNotification && e.Kind != EventStatus {
+		return
+	}
+	l := w.lights.Get(e.DevID)
+	if l == nil {
+		return
+	}
+	p := l.point(e)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.points <- p:
+	default:
+	}
+}
+
+// Close stops accepting events and waits for the queued points
+func (w *SinkWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.points)
+	}
+	w.mu.Unlock()
+	<-w.done
+	return nil
+}
+
+// point returns light's state at e, notifications are
+// emitted before being applied so their values are used
+func (l *Light) point(e Event) Point {
+	l.mu.Lock()
+	power, bright, ct, rgb := l.Power, l.Bright, l.CT, l.RGB
+	status := l.Status
+	tags := map[string]string{"id": l.ID, "name": l.Name, "model": l.Model}
+	l.mu.Unlock()
+	if e.Notification != nil {
+		if u, ok := e.Notification.Props(); ok {
+			if u.Power != nil {
+				power = *u.Power
+			}
+			if u.Bright != nil {
+				bright = *u.Bright
+			}
+			if u.CT != nil {
+				ct = *u.CT
+			}
+			if u.RGB != nil {
+				rgb = *u.RGB
+			}
+		}
+	}
+	if e.Status != nil {
+		status = e.Status.To
+	}
+	on := 0
+	if power == "on" {
+		on = 1
+	}
+	return Point{
+		Measurement: sinkMeasurement,
+		Tags:        tags,
+		Fields: map[string]interface{}{
+			"power":     on,
+			"bright":    bright,
+			"ct":        ct,
+			"rgb":       rgb,
+			"reachable": status != OFFLINE,
+		},
+		At: e.At,
+	}
+}
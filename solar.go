@@ -0,0 +1,105 @@
+package yeelight
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var errNoSunEvent = errors.New("Sun does not rise or set that day")
+
+// Coordinates is a geographic position in decimal degrees
+type Coordinates struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+const (
+	julianUnixEpoch = 2440587.5
+	julian2000      = 2451545.0
+	degToRad        = math.Pi / 180
+)
+
+// SunTimes returns sunrise and sunset at c on day's date, in day's
+// location. It fails on polar days and nights
+func SunTimes(day time.Time, c Coordinates) (rise, set time.Time, err error) {
+	y, m, d := day.Date()
+	noon := time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	jd := float64(noon.Unix())/86400 + julianUnixEpoch
+	n := math.Round(jd - julian2000 + 0.0008)
+
+	// Mean solar time, anomaly and ecliptic longitude
+	js := n - c.Longitude/360
+	ma := math.Mod(357.5291+0.98560028*js, 360)
+	mr := ma * degToRad
+	eq := 1.9148*math.Sin(mr) + 0.02*math.Sin(2*mr) + 0.0003*math.Sin(3*mr)
+	lambda := math.Mod(ma+eq+180+102.9372, 360) * degToRad
+	transit := julian2000 + js + 0.0053*math.Sin(mr) - 0.0069*math.Sin(2*lambda)
+
+	// Declination and hour angle with refraction correction
+	sinDec := math.Sin(lambda) * math.Sin(23.44*degToRad)
+	cosDec := math.Cos(math.Asin(sinDec))
+	lat := c.Latitude * degToRad
+	cosHA := (math.Sin(-0.833*degToRad) - math.Sin(lat)*sinDec) / (math.Cos(lat) * cosDec)
+	if cosHA < -1 || cosHA > 1 {
+		return time.Time{}, time.Time{}, errNoSunEvent
+	}
+	ha := math.Acos(cosHA) / degToRad
+
+	julianTime := func(j float64) time.Time {
+		sec := (j - julianUnixEpoch) * 86400
+		return time.Unix(int64(sec), 0).In(day.Location())
+	}
+	return julianTime(transit - ha/360), julianTime(transit + ha/360), nil
+}
+
+// ParseSolarTime resolves expr on day's date at c. expr is "sunrise"
+// or "sunset" optionally followed by an offset like "sunset-30m" or
+// "sunrise+1h15m", or a clock time like "22:00"
+func ParseSolarTime(expr string, day time.Time, c Coordinates) (time.Time, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	y, m, d := day.Date()
+	if t, err := time.ParseInLocation("15:04", expr, day.Location()); err == nil {
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+	}
+
+	var base string
+	for _, b := range []string{"sunrise", "sunset"} {
+		if strings.HasPrefix(expr, b) {
+			base = b
+		}
+	}
+	if base == "" {
+		return time.Time{}, fmt.Errorf("%w: solar time %q", errInvalidParam, expr)
+	}
+	var offset time.Duration
+	if rest := strings.TrimPrefix(expr, base); rest != "" {
+		var err error
+		if offset, err = time.ParseDuration(rest); err != nil {
+			return time.Time{}, fmt.Errorf("%w: solar time offset %q", errInvalidParam, rest)
+		}
+	}
+	rise, set, err := SunTimes(day, c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if base == "sunrise" {
+		return rise.Add(offset), nil
+	}
+	return set.Add(offset), nil
+}
+
+// AfterSunset returns a condition true between sunset and the
+// following sunrise at c, usable as a presence behavior condition
+func AfterSunset(c Coordinates) func(time.Time) bool {
+	return func(t time.Time) bool {
+		rise, set, err := SunTimes(t, c)
+		if err != nil {
+			// Polar day or night, there is no sunset to compare
+			return false
+		}
+		return t.Before(rise) || t.After(set)
+	}
+}
@@ -0,0 +1,83 @@
+package yeelight
+
+import (
+	"io"
+	"net/http"
+	"sync"
+
+	ssdp "github.com/pulento/go-ssdp"
+)
+
+// SearchProvider sends SSDP M-SEARCH requests to addr waiting wait
+// seconds and returns the headers of all the responses
+type SearchProvider interface {
+	Search(addr, searchType string, wait int, localAddr string) ([]http.Header, error)
+}
+
+// MonitorProvider listens SSDP announcements on addr calling
+// alive with their headers until the returned Closer is closed
+type MonitorProvider interface {
+	Monitor(addr string, alive func(header http.Header)) (io.Closer, error)
+}
+
+// goSSDP implements the providers with github.com/pulento/go-ssdp
+type goSSDP struct{}
+
+func (goSSDP) Search(addr, searchType string, wait int, localAddr string) ([]http.Header, error) {
+	err := ssdp.SetMulticastSendAddrIPv4(addr)
+	if err != nil {
+		return nil, err
+	}
+	list, err := ssdp.Search(searchType, wait, localAddr)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]http.Header, 0, len(list))
+	for _, srv := range list {
+		headers = append(headers, srv.Header())
+	}
+	return headers, nil
+}
+
+func (goSSDP) Monitor(addr string, alive func(header http.Header)) (io.Closer, error) {
+	err := ssdp.SetMulticastRecvAddrIPv4(addr)
+	if err != nil {
+		return nil, err
+	}
+	mon := &ssdp.Monitor{
+		Alive: func(m *ssdp.AliveMessage) {
+			alive(m.Header())
+		},
+	}
+	err = mon.Start()
+	if err != nil {
+		return nil, err
+	}
+	return mon, nil
+}
+
+var (
+	providersMu     sync.RWMutex
+	searchProvider  SearchProvider  = goSSDP{}
+	monitorProvider MonitorProvider = goSSDP{}
+)
+
+// SetSSDPProviders replaces the SSDP implementation used for discovery,
+// nil values restore the default go-ssdp based ones
+func SetSSDPProviders(s SearchProvider, m MonitorProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if s == nil {
+		s = goSSDP{}
+	}
+	if m == nil {
+		m = goSSDP{}
+	}
+	searchProvider, monitorProvider = s, m
+}
+
+func ssdpProviders() (SearchProvider, MonitorProvider) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return searchProvider, monitorProvider
+}
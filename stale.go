@@ -0,0 +1,71 @@
+package yeelight
+
+import (
+	"context"
+	"time"
+)
+
+// watchStale periodically marks offline the lights gone stale
+// until the Manager is closed
+func (m *Manager) watchStale() {
+	t := m.cfg.clock.NewTicker(m.cfg.refreshPeriod)
+	defer t.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-t.C():
+			m.lights.ExpireStale(now)
+		}
+	}
+}
+
+// WatchStale runs ExpireStale every period until ctx is done, for
+// collections fed by SSDPMonitor without a Manager
+func (ls *Lights) WatchStale(ctx context.Context, period time.Duration) {
+	t := ls.clock.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C():
+			ls.ExpireStale(now)
+		}
+	}
+}
+
+// ExpireStale marks offline the lights stale at now emitting
+// EventStale once per light until it announces itself again,
+// it returns the lights gone stale
+func (ls *Lights) ExpireStale(now time.Time) []*Light {
+	var expired []*Light
+	ls.Range(func(id string, l *Light) bool {
+		stale := l.Stale(now)
+		ls.mu.Lock()
+		_, was := ls.stale[id]
+		if stale && !was {
+			ls.stale[id] = l.getStatus()
+		} else if !stale {
+			delete(ls.stale, id)
+		}
+		ls.mu.Unlock()
+		if stale && !was {
+			l.log().Warnf("Light stale, no announcement since %s", l.ExpiresAt.Add(-l.MaxAge()))
+			l.setStatus(OFFLINE)
+			l.emit(Event{Kind: EventStale})
+			expired = append(expired, l)
+		}
+		return true
+	})
+	return expired
+}
+
+// revived forgets light id went stale returning its status back then
+func (ls *Lights) revived(id string) (Status, bool) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	s, ok := ls.stale[id]
+	delete(ls.stale, id)
+	return s, ok
+}
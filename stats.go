@@ -0,0 +1,89 @@
+package yeelight
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// counters are light's activity counters, updated atomically
+type counters struct {
+	sent          atomic.Uint64
+	matched       atomic.Uint64
+	unmatched     atomic.Uint64
+	notifications atomic.Uint64
+	reconnects    atomic.Uint64
+}
+
+// Stats is a snapshot of a light's activity counters
+type Stats struct {
+	CommandsSent     uint64 `json:"commands_sent"`
+	ResultsMatched   uint64 `json:"results_matched"`
+	ResultsUnmatched uint64 `json:"results_unmatched"`
+	Notifications    uint64 `json:"notifications"`
+	Reconnects       uint64 `json:"reconnects"`
+	Dropped          uint64 `json:"dropped"`
+	PendingCalls     int    `json:"pending_calls"`
+	QueueDepth       int    `json:"queue_depth"`
+}
+
+// add sums s2 into s
+func (s *Stats) add(s2 Stats) {
+	s.CommandsSent += s2.CommandsSent
+	s.ResultsMatched += s2.ResultsMatched
+	s.ResultsUnmatched += s2.ResultsUnmatched
+	s.Notifications += s2.Notifications
+	s.Reconnects += s2.Reconnects
+	s.Dropped += s2.Dropped
+	s.PendingCalls += s2.PendingCalls
+	s.QueueDepth += s2.QueueDepth
+}
+
+// Stats returns a snapshot of light's activity counters,
+// QueueDepth is the number of commands in the offline queue
+func (l *Light) Stats() Stats {
+	l.mu.Lock()
+	pending, queued := len(l.Calls), len(l.queue)
+	l.mu.Unlock()
+	return Stats{
+		CommandsSent:     l.counters.sent.Load(),
+		ResultsMatched:   l.counters.matched.Load(),
+		ResultsUnmatched: l.counters.unmatched.Load(),
+		Notifications:    l.counters.notifications.Load(),
+		Reconnects:       l.counters.reconnects.Load(),
+		Dropped:          l.dropped.Load(),
+		PendingCalls:     pending,
+		QueueDepth:       queued,
+	}
+}
+
+// ManagerStats are the totals of the lights known by a Manager
+type ManagerStats struct {
+	Stats
+	Lights       int              `json:"lights"`
+	EventsQueued int              `json:"events_queued"`
+	PerLight     map[string]Stats `json:"per_light"`
+}
+
+// Stats returns a snapshot of the activity of Manager's lights
+func (m *Manager) Stats() ManagerStats {
+	st := ManagerStats{
+		EventsQueued: len(m.events),
+		PerLight:     make(map[string]Stats),
+	}
+	for l := range m.All() {
+		ls := l.Stats()
+		st.Lights++
+		st.add(ls)
+		st.PerLight[l.ID] = ls
+	}
+	return st
+}
+
+// Publish exports Manager's Stats with expvar under name, served
+// at /debug/vars by the default mux. Like expvar.Publish it panics
+// if name is already in use
+func (m *Manager) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.Stats()
+	}))
+}
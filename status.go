@@ -0,0 +1,132 @@
+package yeelight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var statusNames = map[Status]string{
+	OFFLINE:  "offline",
+	SSDP:     "ssdp",
+	UPDATING: "updating",
+	ONLINE:   "online",
+}
+
+// StatusChange is a connectivity transition of a light
+type StatusChange struct {
+	From Status    `json:"from"`
+	To   Status    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// String returns the readable name of the status
+func (s Status) String() string {
+	if n, ok := statusNames[s]; ok {
+		return n
+	}
+	return fmt.Sprintf("status(%d)", int32(s))
+}
+
+// MarshalJSON encodes the status as its readable name
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// MarshalText encodes the status as its name, for map keys and text formats
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalJSON decodes a status from its name, numeric
+// values are accepted for compatibility with older outputs
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var n int32
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*s = Status(n)
+		return nil
+	}
+	return s.UnmarshalText([]byte(name))
+}
+
+// UnmarshalText decodes a status from its name
+func (s *Status) UnmarshalText(text []byte) error {
+	name := string(text)
+	for k, v := range statusNames {
+		if v == name {
+			*s = k
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown status %q", errInvalidParam, name)
+}
+
+// setStatus updates light's status recording the transition
+func (l *Light) setStatus(s Status) {
+	l.mu.Lock()
+	old := l.Status
+	l.Status = s
+	if old == s {
+		l.mu.Unlock()
+		return
+	}
+	change := StatusChange{From: old, To: s, At: l.now()}
+	l.history = append(l.history, change)
+	if len(l.history) > l.cfg.historyLen {
+		l.history = l.history[len(l.history)-l.cfg.historyLen:]
+	}
+	handlers := l.statusHandlers
+	l.mu.Unlock()
+
+	l.emit(Event{Kind: EventStatus, Status: &change})
+
+	for _, f := range handlers {
+		f(old, s)
+	}
+}
+
+// OnStatusChange registers f to be called on every status
+// transition, f must not block as it runs on light's goroutines
+func (l *Light) OnStatusChange(f func(old, new Status)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statusHandlers = append(l.statusHandlers, f)
+}
+
+// StatusChanges returns a channel receiving light's status transitions
+// until ctx is done, then it is closed. Transitions are dropped if
+// more than buffer are pending
+func (l *Light) StatusChanges(ctx context.Context, buffer int) <-chan StatusChange {
+	c := make(chan StatusChange, buffer)
+	s := l.subscribe(ctx, "status", func() int { return len(c) }, func() { close(c) })
+	l.OnStatusChange(func(old, new Status) {
+		s.send(func() {
+			select {
+			case c <- StatusChange{From: old, To: new, At: l.now()}:
+			default:
+			}
+		})
+	})
+	return c
+}
+
+// getStatus returns light's current status
+func (l *Light) getStatus() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Status
+}
+
+// StatusHistory returns light's recent status transitions, oldest first
+func (l *Light) StatusHistory() []StatusChange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := make([]StatusChange, len(l.history))
+	copy(h, l.history)
+	return h
+}
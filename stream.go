@@ -0,0 +1,157 @@
+package yeelight
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Search time used by SearchStream with providers unable to stream
+// and contexts without deadline
+const defaultStreamWait = 3
+
+// StreamSearchProvider is a SearchProvider able to return responses
+// as they arrive until ctx is done
+type StreamSearchProvider interface {
+	SearchProvider
+	SearchStream(ctx context.Context, addr, searchType, localAddr string) (<-chan http.Header, error)
+}
+
+// SearchStream sends an M-SEARCH returning lights as they answer, so
+// the first one can be used without waiting for the search window.
+// The channel is closed when ctx is done, each light is sent once
+func SearchStream(ctx context.Context, localAddr string) (<-chan *Light, error) {
+	return searchStream(ctx, mcastAddress, localAddr)
+}
+
+// searchStream is SearchStream on the multicast address mcast
+func searchStream(ctx context.Context, mcast, localAddr string) (<-chan *Light, error) {
+	search, _ := ssdpProviders()
+	var headers <-chan http.Header
+	if s, ok := search.(StreamSearchProvider); ok {
+		var err error
+		headers, err = s.SearchStream(ctx, mcast, searchType, localAddr)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		headers = bufferedSearch(ctx, search, mcast, localAddr)
+	}
+	if ipv6Enabled() {
+		v6, err := goSSDP{}.SearchStream(ctx, mcastAddressV6, searchType, "")
+		if err != nil {
+			log().Errorf("Error searching on IPv6: %s", err)
+		} else {
+			headers = mergeHeaders(ctx, headers, v6)
+		}
+	}
+
+	lights := make(chan *Light)
+	go func() {
+		defer close(lights)
+		seen := make(map[string]bool)
+		for h := range headers {
+			light, err := Parse(h)
+			if err != nil {
+				log().Errorf("Invalid response from %s: %s", h.Get("Location"), err)
+				continue
+			}
+			if seen[light.ID] {
+				continue
+			}
+			seen[light.ID] = true
+			light.setStatus(SSDP)
+			select {
+			case lights <- light:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lights, nil
+}
+
+// bufferedSearch adapts a blocking SearchProvider to a stream
+func bufferedSearch(ctx context.Context, search SearchProvider, mcast, localAddr string) <-chan http.Header {
+	wait := defaultStreamWait
+	if d, ok := ctx.Deadline(); ok {
+		wait = int(time.Until(d) / time.Second)
+		if wait < 1 {
+			wait = 1
+		}
+	}
+	out := make(chan http.Header)
+	go func() {
+		defer close(out)
+		list, err := search.Search(mcast, searchType, wait, localAddr)
+		if err != nil {
+			log().Errorf("Error searching: %s", err)
+			return
+		}
+		for _, h := range list {
+			select {
+			case out <- h:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// SearchStream implements StreamSearchProvider with a plain UDP socket
+func (goSSDP) SearchStream(ctx context.Context, addr, searchType, localAddr string) (<-chan http.Header, error) {
+	network := udpNetwork(addr)
+	raddr, err := net.ResolveUDPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	var laddr *net.UDPAddr
+	if localAddr != "" {
+		if _, _, err := net.SplitHostPort(localAddr); err != nil {
+			localAddr = net.JoinHostPort(localAddr, "0")
+		}
+		if laddr, err = net.ResolveUDPAddr(network, localAddr); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("M-SEARCH * HTTP/1.1\r\nHOST: %s\r\nMAN: \"ssdp:discover\"\r\nST: %s\r\n\r\n", addr, searchType)
+	if _, err := conn.WriteTo([]byte(msg), raddr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	out := make(chan http.Header)
+	go func() {
+		defer close(out)
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+		defer conn.Close()
+		buf := make([]byte, 2048)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+			if err != nil {
+				continue
+			}
+			resp.Body.Close()
+			select {
+			case out <- resp.Header:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
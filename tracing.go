@@ -0,0 +1,118 @@
+package yeelight
+
+import (
+	"context"
+	"sync"
+)
+
+// Attr is a key/value pair attached to spans
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer starts spans around commands, connections and discovery,
+// see the oteladapter package for an implementation using OpenTelemetry
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// Span is an operation being traced, it is ended once
+type Span interface {
+	SetAttrs(attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+// nopTracer starts spans doing nothing
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttrs(...Attr)  {}
+func (nopSpan) RecordError(error) {}
+func (nopSpan) End()              {}
+
+// Tracer used when none is configured, a no-op by default
+var (
+	stdTracerMu sync.RWMutex
+	stdTracer   Tracer = nopTracer{}
+)
+
+// SetTracer sets the package default tracer, used by Search
+// and by lights and managers without their own tracer
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = nopTracer{}
+	}
+	stdTracerMu.Lock()
+	stdTracer = t
+	stdTracerMu.Unlock()
+}
+
+// tracer returns the package default tracer
+func tracer() Tracer {
+	stdTracerMu.RLock()
+	defer stdTracerMu.RUnlock()
+	return stdTracer
+}
+
+// WithTracer sets the tracer of a Manager or Light
+func WithTracer(t Tracer) Option {
+	return func(c *config) {
+		c.tracer = t
+	}
+}
+
+// getTracer returns the tracer configured in c or the package one
+func (c *config) getTracer() Tracer {
+	if c.tracer == nil {
+		return tracer()
+	}
+	return c.tracer
+}
+
+// startSpan starts a span with light's ID and address
+func (l *Light) startSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	attrs = append(attrs,
+		Attr{"yeelight.id", l.ID},
+		Attr{"yeelight.address", l.Address})
+	return l.cfg.getTracer().Start(ctx, name, attrs...)
+}
+
+// endSpan records err, if any, and ends sp
+func endSpan(sp Span, err error) {
+	if err != nil {
+		sp.RecordError(err)
+	}
+	sp.End()
+}
+
+// resultAttrs describes r as span attributes, a nil r timed out
+func resultAttrs(r *Result) []Attr {
+	switch {
+	case r == nil:
+		return []Attr{{"yeelight.result", "timeout"}}
+	case r.Error != nil:
+		return []Attr{
+			{"yeelight.result", "error"},
+			{"yeelight.result.code", r.Error.Code},
+		}
+	}
+	return []Attr{{"yeelight.result", "ok"}, {"yeelight.result.code", 0}}
+}
+
+// requestAttrs describes the pending command id as span attributes
+func (l *Light) requestAttrs(id int32) []Attr {
+	attrs := []Attr{{"yeelight.request", int(id)}}
+	l.mu.Lock()
+	if cmd := l.Calls[id]; cmd != nil {
+		attrs = append(attrs, Attr{"yeelight.method", cmd.Method})
+	}
+	l.mu.Unlock()
+	return attrs
+}
@@ -0,0 +1,159 @@
+package yeelight
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Transport is the link a light's commands and messages travel over,
+// the default is a TCP connection to the light's control port
+type Transport interface {
+	// Dial connects to addr replacing any previous connection
+	Dial(addr string) error
+	// Send writes an encoded command
+	Send(b []byte) error
+	// Receive decodes the next message sent by the light into v
+	Receive(v interface{}) error
+	// Close closes the connection, pending Receives fail
+	Close() error
+}
+
+// WithTransport sets the constructor of lights' transports,
+// every light gets its own Transport
+func WithTransport(f func() Transport) Option {
+	return func(c *config) {
+		c.transport = f
+	}
+}
+
+// tcpTransport is the default Transport
+type tcpTransport struct {
+	cfg    *config
+	mu     sync.Mutex
+	conn   *net.TCPConn
+	reader *bufio.Reader
+	dec    *json.Decoder
+}
+
+func newTCPTransport(cfg *config) *tcpTransport {
+	return &tcpTransport{cfg: cfg}
+}
+
+// Dial implements Transport
+func (t *tcpTransport) Dial(addr string) error {
+	d, err := t.cfg.newDialer()
+	if err != nil {
+		return err
+	}
+	cn, err := d.Dial("tcp", addr)
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("%w: %w", ErrLANControlDisabled, err)
+	}
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(cn, t.cfg.readerSize)
+	t.mu.Lock()
+	old := t.conn
+	t.conn = cn.(*net.TCPConn)
+	t.reader = reader
+	t.dec = json.NewDecoder(reader)
+	t.mu.Unlock()
+	if old != nil {
+		// Clean connection on reconnects
+		old.Close()
+	}
+	return nil
+}
+
+// Send implements Transport
+func (t *tcpTransport) Send(b []byte) error {
+	conn := t.current()
+	if conn == nil {
+		return errNotConnected
+	}
+	if t.cfg.writeTimeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(t.cfg.writeTimeout))
+	}
+	_, err := conn.Write(b)
+	return err
+}
+
+// Receive implements Transport
+func (t *tcpTransport) Receive(v interface{}) error {
+	t.mu.Lock()
+	conn, dec := t.conn, t.dec
+	t.mu.Unlock()
+	if conn == nil {
+		return errNotConnected
+	}
+	if t.cfg.readTimeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(t.cfg.readTimeout))
+	}
+	return dec.Decode(v)
+}
+
+// Close implements Transport
+func (t *tcpTransport) Close() error {
+	conn := t.current()
+	if conn == nil {
+		return errNotConnected
+	}
+	return conn.Close()
+}
+
+// SetReadDeadline bounds the Receives in course and to come
+func (t *tcpTransport) SetReadDeadline(d time.Time) error {
+	conn := t.current()
+	if conn == nil {
+		return errNotConnected
+	}
+	return conn.SetReadDeadline(d)
+}
+
+// LocalAddr returns the local address of the connection
+func (t *tcpTransport) LocalAddr() net.Addr {
+	conn := t.current()
+	if conn == nil {
+		return nil
+	}
+	return conn.LocalAddr()
+}
+
+// newDialer returns the dialer configured to connect to lights
+func (c *config) newDialer() (*net.Dialer, error) {
+	d := &net.Dialer{}
+	if c.dialer != nil {
+		*d = *c.dialer
+	}
+	if d.Timeout == 0 {
+		d.Timeout = c.connTimeout
+	}
+	if d.KeepAlive == 0 {
+		d.KeepAlive = c.keepAlive
+	}
+	if c.bindAddr != "" {
+		addr := c.bindAddr
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			addr = net.JoinHostPort(addr, "0")
+		}
+		local, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		d.LocalAddr = local
+	}
+	return d, nil
+}
+
+func (t *tcpTransport) current() *net.TCPConn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn
+}
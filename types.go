@@ -4,25 +4,33 @@ import (
 	"bufio"
 	"errors"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// PowerState is light's power
+type PowerState int
+
 // Light's power
 const (
 	// OFF Light off
-	OFF = iota
+	OFF PowerState = iota
 	// ON Light on
-	ON = iota
-	// OFFLINE Light unreachable
-	UNKNOWN = iota
+	ON
+	// UNKNOWN Light's power not known yet
+	UNKNOWN
 )
 
+// Status is light's connectivity
+type Status int32
+
 // Light's connectivity
 const (
-	OFFLINE  = 0
-	SSDP     = 1
-	UPDATING = 2
-	ONLINE   = 3
+	OFFLINE  Status = 0
+	SSDP     Status = 1
+	UPDATING Status = 2
+	ONLINE   Status = 3
 )
 
 // Light is the light :)
@@ -40,15 +48,67 @@ type Light struct {
 	RGB          int             `json:"rgb"`
 	Hue          int             `json:"hue"`
 	ColorMode    int             `json:"color_mode"`
+	Flowing      bool            `json:"flowing"`
+	FlowParams   string          `json:"flow_params"`
+	CurrentFlow  *Flow           `json:"current_flow,omitempty"`
+	DelayOff     int             `json:"delayoff"`
+	MusicOn      int             `json:"music_on"`
+	SaveState    int             `json:"save_state"`
+	ActiveMode   int             `json:"active_mode"`
+	NlBr         int             `json:"nl_br"`
+	BgPower      string          `json:"bg_power"`
+	BgFlowing    int             `json:"bg_flowing"`
+	BgFlowParams string          `json:"bg_flow_params"`
+	BgCT         int             `json:"bg_ct"`
+	BgLMode      int             `json:"bg_lmode"`
+	BgBright     int             `json:"bg_bright"`
+	BgRGB        int             `json:"bg_rgb"`
+	BgHue        int             `json:"bg_hue"`
+	BgSat        int             `json:"bg_sat"`
 	Support      map[string]bool `json:"support"`
 	ReqCount     int32           `json:"reqcount"`
 	LastSeen     int64           `json:"lastseen"`
-	Status       int32           `json:"status"`
-	refresh      <-chan time.Time
-	Conn         *net.TCPConn       `json:"-"`
-	Calls        map[int32]*Command `json:"-"`
-	ResC         chan *Result       `json:"-"`
-	Reader       *bufio.Reader      `json:"-"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	Status       Status          `json:"status"`
+	// Signals the listen loop to re-arm its refresh timer
+	resetRefresh   chan struct{}
+	mu             sync.Mutex
+	history        []StatusChange
+	statusHandlers []func(old, new Status)
+	anomalies      []func(Anomaly)
+	limiter        *rateLimiter
+	autoMusic      bool
+	musicAuto      bool
+	musicRetry     time.Time
+	flowing        int
+	recent         []time.Time
+	calibration    *Calibration
+	music          net.Conn
+	cfg            config
+	opts           []Option
+	queue          []queuedCommand
+	propCache      map[string]cachedProp
+	futures        map[int32]*call
+	rtt            latencies
+	propHandlers   map[string][]func(PropertyEvent)
+	stateHandlers  []func(StateChange)
+	subs           []*subscription
+	frozen         bool
+	discoveredAt   time.Time
+	listener       *Listener
+	dropped        atomic.Uint64
+	transport      Transport
+	connGen        atomic.Uint64
+	quietRefreshes int
+	counters       counters
+	states         *stateRing
+	writes         chan writeRequest
+	pendingWrites  int
+	wmu            sync.Mutex
+	Conn           *net.TCPConn       `json:"-"`
+	Calls          map[int32]*Command `json:"-"`
+	ResC           chan *Result       `json:"-"`
+	Reader         *bufio.Reader      `json:"-"`
 }
 
 // Command JSON commands sent to lights
@@ -92,4 +152,10 @@ var (
 	errCommandNotSupported   = errors.New("Command not supported")
 	errNotConnected          = errors.New("Light not connected")
 	errInvalidParam          = errors.New("Invalid parameter value")
+	errManagerClosed         = errors.New("Manager closed")
+	errNoLocalAddr           = errors.New("Cannot find local address")
+	errInvalidResult         = errors.New("Invalid result value")
+	errMissingHeader         = errors.New("Missing header")
+	errMiIOPacket            = errors.New("Malformed MiIO packet")
+	errNotALight             = errors.New("Device is not a light")
 )
@@ -0,0 +1,137 @@
+package yeelight
+
+import "fmt"
+
+// Protocol limits of hue and saturation
+const (
+	maxHue = 359
+	maxSat = 100
+)
+
+func (m *Model) validHSV(hue, sat int) error {
+	if !m.Color {
+		return fmt.Errorf("%w: model %s has no color support", errCommandNotSupported, m.Name)
+	}
+	if hue < 0 || hue > maxHue {
+		return fmt.Errorf("%w: hue %d out of range 0-%d", errInvalidParam, hue, maxHue)
+	}
+	if sat < 0 || sat > maxSat {
+		return fmt.Errorf("%w: saturation %d out of range 0-%d", errInvalidParam, sat, maxSat)
+	}
+	return nil
+}
+
+// ClampBright returns bright limited to the model's range
+func (m *Model) ClampBright(bright int) int {
+	return clamp(bright, m.MinBright, m.MaxBright)
+}
+
+// ClampCT returns ct limited to the model's range
+func (m *Model) ClampCT(ct int) int {
+	return clamp(ct, m.MinCT, m.MaxCT)
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// validate checks the params of cmd against the limits of light's
+// model so bad values are reported instead of the light's opaque
+// error. Background light methods are not checked as their ranges
+// differ from the model's
+func (l *Light) validate(cmd *Command) error {
+	m := l.ModelSpec()
+	p := cmd.Params
+	switch cmd.Method {
+	case "set_bright":
+		return checkParams(p, func(v []int) error { return m.validBright(v[0]) }, 1)
+	case "set_ct_abx":
+		return checkParams(p, func(v []int) error { return m.validCT(v[0]) }, 1)
+	case "set_rgb":
+		return checkParams(p, func(v []int) error { return m.validRGB(uint32(v[0])) }, 1)
+	case "set_hsv":
+		return checkParams(p, func(v []int) error { return m.validHSV(v[0], v[1]) }, 2)
+	case "set_scene":
+		if len(p) == 0 {
+			return fmt.Errorf("%w: set_scene without class", errInvalidParam)
+		}
+		args := p[1:]
+		switch p[0] {
+		case "color":
+			return checkParams(args, func(v []int) error {
+				if err := m.validRGB(uint32(v[0])); err != nil {
+					return err
+				}
+				return m.validBright(v[1])
+			}, 2)
+		case "hsv":
+			return checkParams(args, func(v []int) error {
+				if err := m.validHSV(v[0], v[1]); err != nil {
+					return err
+				}
+				return m.validBright(v[2])
+			}, 3)
+		case "ct":
+			return checkParams(args, func(v []int) error {
+				if err := m.validCT(v[0]); err != nil {
+					return err
+				}
+				return m.validBright(v[1])
+			}, 2)
+		}
+	}
+	return nil
+}
+
+// checkParams calls check with the first n params as ints,
+// params of other types are left for the light to judge
+func checkParams(params []interface{}, check func([]int) error, n int) error {
+	if len(params) < n {
+		return fmt.Errorf("%w: %d params, expected at least %d", errInvalidParam, len(params), n)
+	}
+	v := make([]int, n)
+	for i := range v {
+		var ok bool
+		if v[i], ok = numParam(params[i]); !ok {
+			return nil
+		}
+	}
+	return check(v)
+}
+
+// numParam returns a numeric command param as an int
+func numParam(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case uint:
+		return int(n), true
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		return int(n), true
+	case uint64:
+		return int(n), true
+	case float32:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
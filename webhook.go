@@ -0,0 +1,197 @@
+package yeelight
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Header carrying the HMAC-SHA256 of webhook payloads
+const signatureHeader = "X-Yeelight-Signature"
+
+// Webhook is a URL receiving light events as JSON POSTs
+type Webhook struct {
+	URL string
+	// Secret signs payloads with HMAC-SHA256 in the
+	// X-Yeelight-Signature header as "sha256=<hex>", if set
+	Secret string
+	// Kinds are the events sent, all if empty
+	Kinds []EventKind
+	// Props limits notifications to the ones with any of these
+	// properties, like "power", all notifications if empty
+	Props []string
+}
+
+// match returns true if e must be sent to h
+func (h *Webhook) match(e Event) bool {
+	if len(h.Kinds) > 0 {
+		found := false
+		for _, k := range h.Kinds {
+			found = found || k == e.Kind
+		}
+		if !found {
+			return false
+		}
+	}
+	if e.Kind != EventNotification || len(h.Props) == 0 {
+		return true
+	}
+	for _, p := range h.Props {
+		if _, ok := e.Notification.Params[p]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature of payload with secret
+// as sent in the X-Yeelight-Signature header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is an event pending to be posted to a webhook
+type delivery struct {
+	hook    *Webhook
+	kind    EventKind
+	payload []byte
+}
+
+// WebhookDispatcher is an EventHandler posting events to webhooks.
+// Deliveries run on their own goroutine retrying failures so slow
+// endpoints never block lights
+type WebhookDispatcher struct {
+	hooks  []Webhook
+	queue  chan delivery
+	done   chan struct{}
+	mu     sync.Mutex
+	closed bool
+	// Client posts the payloads, http.DefaultClient if nil
+	Client *http.Client
+	// Retry controls the retries of failed deliveries
+	Retry RetryPolicy
+	// OnError is called with deliveries given up, if set
+	OnError func(url string, err error)
+}
+
+// Retries of webhook deliveries by default
+var defaultWebhookRetry = RetryPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+	Jitter:       0.2,
+	MaxAttempts:  3,
+}
+
+// NewWebhookDispatcher returns a dispatcher posting to hooks queuing
+// up to buffer deliveries, more are dropped. Pass it to
+// WithEventHandler and call Close when done
+func NewWebhookDispatcher(buffer int, hooks ...Webhook) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		hooks: hooks,
+		queue: make(chan delivery, buffer),
+		done:  make(chan struct{}),
+		Retry: defaultWebhookRetry,
+	}
+	go d.run()
+	return d
+}
+
+// HandleEvent implements EventHandler
+func (d *WebhookDispatcher) HandleEvent(e Event) {
+	var payload []byte
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	for i := range d.hooks {
+		h := &d.hooks[i]
+		if !h.match(e) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(e); err != nil {
+				log().Errorf("Error encoding webhook payload: %s", err)
+				return
+			}
+		}
+		select {
+		case d.queue <- delivery{hook: h, kind: e.Kind, payload: payload}:
+		default:
+			log().WithField("url", h.URL).Warnf("Webhook queue full, event dropped")
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued deliveries
+func (d *WebhookDispatcher) Close() error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	<-d.done
+	return nil
+}
+
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+	for dl := range d.queue {
+		if err := d.deliver(dl); err != nil {
+			log().WithFields(Fields{"url": dl.hook.URL, "error": err}).Errorf("Webhook delivery failed")
+			if d.OnError != nil {
+				d.OnError(dl.hook.URL, err)
+			}
+		}
+	}
+}
+
+// deliver posts dl retrying network errors and 429 and 5xx answers
+func (d *WebhookDispatcher) deliver(dl delivery) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		if retry, err = d.post(dl); err == nil || !retry || attempt >= d.Retry.MaxAttempts {
+			break
+		}
+		time.Sleep(ReconnectPolicy(d.Retry).delay(attempt))
+	}
+	return err
+}
+
+// post makes one delivery attempt, it returns true if worth retrying
+func (d *WebhookDispatcher) post(dl delivery) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, dl.hook.URL, bytes.NewReader(dl.payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Yeelight-Event", dl.kind.String())
+	if dl.hook.Secret != "" {
+		req.Header.Set(signatureHeader, Sign(dl.hook.Secret, dl.payload))
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return false, nil
+}
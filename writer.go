@@ -0,0 +1,70 @@
+package yeelight
+
+import "time"
+
+// Commands a light's writer holds before callers block
+const writeBacklog = 16
+
+// Idle time after which a light's writer goroutine ends,
+// the next command starts a new one
+const writerIdle = 30 * time.Second
+
+// writeRequest is a command for the writer, its error is sent
+// to done once written
+type writeRequest struct {
+	cmd  *Command
+	done chan error
+}
+
+// write sends cmd through light's writer so concurrent commands are
+// rate limited and written in order, whole frames at a time. set_music
+// is written right away as the writer may be waiting on it to switch
+// to music mode when throttled
+func (l *Light) write(cmd *Command) error {
+	if cmd.Method == "set_music" {
+		return l.send(cmd)
+	}
+	req := writeRequest{cmd: cmd, done: make(chan error, 1)}
+	l.mu.Lock()
+	if l.writes == nil {
+		l.writes = make(chan writeRequest, writeBacklog)
+		go l.writer(l.writes)
+	}
+	writes := l.writes
+	l.pendingWrites++
+	l.mu.Unlock()
+	writes <- req
+	return <-req.done
+}
+
+// writer throttles and sends the requests of writes until
+// it has been idle for writerIdle. The clock is read under the
+// lock as the Manager configures lights already writing
+func (l *Light) writer(writes chan writeRequest) {
+	l.mu.Lock()
+	clock := l.clock()
+	l.mu.Unlock()
+	idle := clock.After(writerIdle)
+	for {
+		select {
+		case req := <-writes:
+			l.throttle(req.cmd.Method)
+			req.done <- l.send(req.cmd)
+			l.mu.Lock()
+			l.pendingWrites--
+			clock = l.clock()
+			l.mu.Unlock()
+			idle = clock.After(writerIdle)
+		case <-idle:
+			l.mu.Lock()
+			if l.pendingWrites == 0 {
+				l.writes = nil
+				l.mu.Unlock()
+				return
+			}
+			clock = l.clock()
+			l.mu.Unlock()
+			idle = clock.After(writerIdle)
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package yeelight
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
@@ -11,9 +12,6 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
-
-	ssdp "github.com/pulento/go-ssdp"
-	log "github.com/sirupsen/logrus"
 )
 
 var (
@@ -26,32 +24,32 @@ var (
 )
 
 // Search searches and update lights for some time using SSDP and
-// fills the map with new lights found indexed by its ID. lightfound
+// adds new lights found to the collection. lightfound
 // is called with the newly found light, usually to start listening it
-func Search(time int, localAddr string, lights map[string]*Light, lightfound func(light *Light)) error {
-	//ssdp.Logger = log.New(os.Stderr, "[SSDP] ", log.LstdFlags)
-	err := ssdp.SetMulticastSendAddrIPv4(mcastAddress)
+func Search(time int, localAddr string, lights *Lights, lightfound func(light *Light)) (err error) {
+	_, sp := tracer().Start(context.Background(), "yeelight.search", Attr{"yeelight.search.seconds", time})
+	defer func() { endSpan(sp, err) }()
+	search, _ := ssdpProviders()
+	list, err := search.Search(mcastAddress, searchType, time, localAddr)
 	if err != nil {
 		return err
 	}
-
-	list, err := ssdp.Search(searchType, time, localAddr)
-	if err != nil {
-		return err
+	sp.SetAttrs(Attr{"yeelight.responses", len(list)})
+	if ipv6Enabled() {
+		list = append(list, searchV6(time, "")...)
 	}
 
-	for _, srv := range list {
-		light, err := Parse(srv.Header())
+	for _, header := range list {
+		light, err := Parse(header)
 		if err != nil {
-			log.Errorf("Invalid response from %s: %s", srv.Location, err)
+			log().Errorf("Invalid response from %s: %s", header.Get("Location"), err)
 			return err
 		}
+		// Light found by SSDP
+		light.setStatus(SSDP)
 		// Lights respond multiple times to a search or
 		// we only insert new lights
-		if lights[light.ID] == nil {
-			// Light found by SSDP
-			light.Status = SSDP
-			lights[light.ID] = light
+		if _, added := lights.LoadOrStore(light); added {
 			// Call the callback
 			if lightfound != nil {
 				lightfound(light)
@@ -62,47 +60,57 @@ func Search(time int, localAddr string, lights map[string]*Light, lightfound fun
 }
 
 // SSDPMonitor starts listening light's SSDP traffic
-// lightmap is a map of *Light so it can update it with
-// lights found, lightfound is called for each new light found
-func SSDPMonitor(lightmap map[string]*Light, lightfound func(light *Light)) error {
-	err := ssdp.SetMulticastRecvAddrIPv4(mcastAddress)
-	if err != nil {
-		return err
+// lights is updated with the lights found,
+// lightfound is called for each new light found
+func SSDPMonitor(lights *Lights, lightfound func(light *Light)) error {
+	_, err := ssdpMonitor(mcastAddress, lights, lightfound)
+	return err
+}
+
+// ssdpMonitor is SSDPMonitor on addr returning the monitor to stop it
+func ssdpMonitor(addr string, lights *Lights, lightfound func(light *Light)) (io.Closer, error) {
+	_, monitor := ssdpProviders()
+	alive := func(header http.Header) {
+		lightAlive(lights, header, lightfound)
 	}
-	mon := &ssdp.Monitor{
-		Alive: func(m *ssdp.AliveMessage) {
-			lightAlive(lightmap, m, lightfound)
-		},
+	mon, err := monitor.Monitor(addr, alive)
+	if err != nil || !ipv6Enabled() {
+		return mon, err
 	}
-	err = mon.Start()
+	mon6, err := monitorV6(alive)
 	if err != nil {
-		return err
+		log().Errorf("Error monitoring on IPv6: %s", err)
+		return mon, nil
 	}
-	return nil
+	return closers{mon, mon6}, nil
 }
 
-func lightAlive(lm map[string]*Light, m *ssdp.AliveMessage, lightfound func(light *Light)) {
-	light, err := Parse(m.Header())
+func lightAlive(lights *Lights, header http.Header, lightfound func(light *Light)) {
+	light, err := Parse(header)
 	if err != nil {
-		log.Errorf("Invalid SSDP notification from %s: %s", m.Location, err)
+		log().Errorf("Invalid SSDP notification from %s: %s", header.Get("Location"), err)
 		return
 	}
-	//log.Printf("SSDP notification Light %s named %s from %s: %v",
-	//	light.ID, light.Name, m.From.String(), *light)
-	// Add it to the map if is a new light
-	if lm[light.ID] == nil {
-		// Light found by SSDP
-		light.Status = SSDP
-		lm[light.ID] = light
-	} else {
+	// Light found by SSDP
+	light.setStatus(SSDP)
+	// Add it to the collection if is a new light
+	cur, added := lights.LoadOrStore(light)
+	if !added {
 		// Updates existing light
-		Copy(lm[light.ID], light)
+		old := cur.Address
+		Copy(cur, light)
+		if old != cur.Address {
+			go cur.addressChanged(old)
+		}
+		if s, ok := lights.revived(cur.ID); ok && cur.getStatus() == OFFLINE {
+			cur.setStatus(s)
+		}
 	}
-	lm[light.ID].LastSeen = time.Now().Unix()
-	lm[light.ID].refresh = time.After(refreshPeriod)
+	cur.seen()
+	cur.rearmRefresh()
 	// Call the callback
 	if lightfound != nil {
-		lightfound(lm[light.ID])
+		lightfound(cur)
 	}
 }
 
@@ -123,46 +131,55 @@ func Copy(dst *Light, src *Light) {
 	dst.Hue = src.Hue
 	dst.ColorMode = src.ColorMode
 	dst.Support = src.Support
+	dst.discoveredAt = src.discoveredAt
+	dst.ExpiresAt = src.ExpiresAt
 }
 
 // Parse returns a Yeelight based on the
 // HTTP headers of its SSDP response represented by header
 // it returns an error if something goes wrong during parsing
 func Parse(header http.Header) (*Light, error) {
-	addr := header.Get("Location")
-	if !strings.HasPrefix(addr, "yeelight://") {
-		return nil, errWithoutYeelightPrefix
-	}
-
-	fw, err := strconv.Atoi(header.Get("FW_Ver"))
-	bright, err := strconv.Atoi(header.Get("Bright"))
-	sat, err := strconv.Atoi(header.Get("Sat"))
-	ct, err := strconv.Atoi(header.Get("Ct"))
-	rgb, err := strconv.Atoi(header.Get("Rgb"))
-	hue, err := strconv.Atoi(header.Get("Hue"))
-	color, err := strconv.Atoi(header.Get("Color_mode"))
-
+	addr, err := parseLocation(header.Get("Location"))
 	if err != nil {
 		return nil, err
 	}
 
+	id := strings.TrimSpace(header.Get("Id"))
+	if id == "" {
+		return nil, &HeaderError{Header: "Id", Err: errMissingHeader}
+	}
+	// Mono lights don't send the color ones
+	var fw, bright, sat, ct, rgb, hue, color int
+	fields := []struct {
+		name     string
+		dst      *int
+		required bool
+	}{
+		{"FW_Ver", &fw, true},
+		{"Bright", &bright, true},
+		{"Sat", &sat, false},
+		{"Ct", &ct, false},
+		{"Rgb", &rgb, false},
+		{"Hue", &hue, false},
+		{"Color_mode", &color, false},
+	}
+	for _, f := range fields {
+		if *f.dst, err = headerInt(header, f.name, f.required); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create a map of supported commands
-	slist := strings.Split(header.Get("Support"), " ")
+	slist := strings.Fields(header.Get("Support"))
 	support := make(map[string]bool, len(slist))
 	for _, v := range slist {
 		support[v] = true
 	}
 
-	// Workaround for buggy FW. Replace set with set_name
-	if support["set"] {
-		support["set_name"] = true
-		delete(support, "set")
-	}
-
 	light := &Light{
-		Address:      addr[11:],
+		Address:      addr,
 		Name:         header.Get("Name"),
-		ID:           header.Get("Id"),
+		ID:           id,
 		Model:        header.Get("Model"),
 		CacheControl: header.Get("Cache-Control"),
 		FW:           fw,
@@ -177,35 +194,71 @@ func Parse(header http.Header) (*Light, error) {
 		ReqCount:     0,
 		Calls:        make(map[int32]*Command),
 		ResC:         make(chan *Result),
+		cfg:          defaultConfig(),
+		discoveredAt: time.Now(),
+		resetRefresh: make(chan struct{}, 1),
 	}
+	light.ExpiresAt = light.expiresAt()
+	light.patchSupport()
 	return light, nil
 }
 
-// Connect connects to a light
-func (l *Light) Connect() error {
-	l.Status = OFFLINE
-	d := net.Dialer{Timeout: connTimeout}
-	cn, err := d.Dial("tcp", l.Address)
-	if err != nil {
-		return err
+// Connect connects to a light, opts are applied
+// to the light beforehand and kept for reconnects
+func (l *Light) Connect(opts ...Option) (err error) {
+	l.Configure(opts...)
+	_, sp := l.startSpan(context.Background(), "yeelight.connect")
+	defer func() { endSpan(sp, err) }()
+	l.setStatus(OFFLINE)
+	t := l.transport
+	if t == nil {
+		if l.cfg.transport != nil {
+			t = l.cfg.transport()
+		} else {
+			t = newTCPTransport(&l.cfg)
+		}
+		if l.cfg.recorder != nil {
+			t = &recorder{Transport: t, log: l.cfg.recorder}
+		}
 	}
+	if err := t.Dial(l.Address); err != nil {
+		return fmt.Errorf("connect %s: %w", l.Address, err)
+	}
+	l.transport = t
+	l.connGen.Add(1)
+	if tcp, ok := t.(*tcpTransport); ok {
+		// Kept for users of the exported fields
+		tcp.mu.Lock()
+		l.Conn, l.Reader = tcp.conn, tcp.reader
+		tcp.mu.Unlock()
+	}
+	l.seen()
+	l.rearmRefresh()
+	l.setStatus(ONLINE)
+	go l.flushQueue()
+	return nil
+}
 
-	if l.Conn != nil {
-		// Clean connection on reconnects
-		l.Close()
+// addressChanged moves light's connection to its new address,
+// usually after a DHCP lease change
+func (l *Light) addressChanged(old string) {
+	l.log().WithField("old", old).Infof("Address changed")
+	l.emit(Event{Kind: EventAddressChanged, Address: l.Address, OldAddress: old})
+	if l.transport == nil {
+		return
+	}
+	if err := l.Connect(); err != nil {
+		l.log().WithField("error", err).Errorf("Error connecting to new address")
 	}
-	l.Conn = cn.(*net.TCPConn)
-	l.Reader = bufio.NewReader(l.Conn)
-	l.LastSeen = time.Now().Unix()
-	l.refresh = time.After(refreshPeriod)
-	l.Status = ONLINE
-	return nil
 }
 
 // Close closes the connection to light
 func (l *Light) Close() error {
-	err := l.Conn.Close()
-	l.Status = OFFLINE
+	if l.transport == nil {
+		return errNotConnected
+	}
+	err := l.transport.Close()
+	l.setStatus(OFFLINE)
 	if err != nil {
 		return err
 	}
@@ -215,215 +268,372 @@ func (l *Light) Close() error {
 // This is to send received data and error on the
 // same channel to the Listener
 type message struct {
-	mess string
-	err  error
+	resnot *ResultNotification
+	err    error
+	gen    uint64
 }
 
-// Receives data from light should span on a goroutine
-func (l *Light) receiver(d chan<- *message, done <-chan bool) {
+// Receives data from light should span on a goroutine,
+// it returns once done is closed and the read in course ends
+func (l *Light) receiver(d chan<- *message, done <-chan struct{}) {
 	for {
+		gen := l.connGen.Load()
+		data, err := l.readMessage()
 		select {
+		case d <- &message{data, err, gen}:
 		case <-done:
 			return
-		default:
-			data, err := l.Message()
-			d <- &message{data, err}
 		}
 	}
 }
 
-// Listen connects to light and listens for events
-// which are sent to notifCh
-func (l *Light) Listen(notifCh chan<- *ResultNotification) (chan<- bool, error) {
-	done := make(chan bool)
+// Listen connects to light with opts and listens for events
+// which are sent to notifCh until the returned Listener is stopped
+func (l *Light) Listen(notifCh chan<- *ResultNotification, opts ...Option) (*Listener, error) {
+	l.Configure(opts...)
+	ln := newListener(l.cfg.notifyQueue, l.cfg.overflow)
 
 	err := l.Connect()
 	if err != nil {
 		return nil, err
 	}
-	lightLog := log.WithFields(log.Fields{
-		"ID":      l.ID,
-		"address": l.Address,
-		"name":    l.Name,
-	})
-	lightLog.Debug("Listening")
-	go func(c net.Conn) {
-		//make sure connection is closed when method returns
-		defer l.Close()
+	l.mu.Lock()
+	l.listener = ln
+	l.mu.Unlock()
+	lightLog := l.log()
+	lightLog.Debugf("Listening")
+	go func() {
+		var lerr error
+		//make sure connection is closed when method returns,
+		//closing it unblocks the receiver
+		defer func() {
+			l.Close()
+			ln.finish(lerr)
+		}()
+
+		ln.fwd.Add(1)
+		go ln.forward(notifCh)
 
 		mes := make(chan *message)
-		rdone := make(chan bool)
+		rdone := make(chan struct{})
 		go l.receiver(mes, rdone)
-		defer func() {
-			rdone <- true
-		}()
+		defer close(rdone)
+		expire := l.clock().NewTicker(time.Second)
+		defer expire.Stop()
+		var alive <-chan time.Time
+		if l.cfg.livenessPeriod > 0 {
+			t := l.clock().NewTicker(l.cfg.livenessPeriod)
+			defer t.Stop()
+			alive = t.C()
+		}
+		// Only this loop arms the refresh timer, see rearmRefresh
+		refresh := l.clock().After(l.refreshInterval())
 
 		for {
-			var resnot *ResultNotification
-
 			select {
-			case <-done:
+			case <-ln.stop:
 				goto exit
-			case <-l.refresh:
-				log.WithField("ID", l.ID).Debug("Periodic Refresh")
-				l.refresh = time.After(refreshPeriod)
+			case <-expire.C():
+				l.expireCalls()
+			case <-alive:
+				if l.idle() >= l.cfg.livenessPeriod {
+					go l.checkAlive(l.connGen.Load())
+				}
+			case <-l.resetRefresh:
+				refresh = l.clock().After(l.refreshInterval())
+			case <-refresh:
+				lightLog.Debugf("Periodic Refresh")
+				l.quietRefreshes++
+				refresh = l.clock().After(l.refreshInterval())
 				go func() {
 					reqid, _ := l.GetProp("power", "bright", "ct", "rgb", "hue", "sat")
-					l.Status = UPDATING
-					l.WaitResult(reqid, commandTimeout)
+					l.setStatus(UPDATING)
+					l.waitResult(reqid, l.cfg.commandTimeout)
 				}()
 			case d := <-mes:
-				if d.err == nil {
-					err := json.Unmarshal([]byte(d.mess), &resnot)
-					if err != nil {
-						log.Error("Error parsing message: ", err)
-					}
+				var typeErr *json.UnmarshalTypeError
+				if errors.As(d.err, &typeErr) {
+					// The stream is still in sync, just skip the message
+					lightLog.Errorf("Error parsing message: %s", d.err)
+				} else if d.err == nil {
+					resnot := d.resnot
 					if resnot.Notification != nil {
 						resnot.Notification.DevID = l.ID
-						l.processNotification(resnot.Notification)
+						l.counters.notifications.Add(1)
+						// Emitted once applied so handlers see the new state
+						changed := l.processNotification(resnot.Notification)
+						l.emit(Event{Kind: EventNotification, Notification: resnot.Notification})
+						if !changed {
+							// Nothing changed, don't bother consumers
+							continue
+						}
 					}
 					if resnot.Result != nil {
 						resnot.Result.DevID = l.ID
 						l.processResult(resnot.Result)
 					}
-					notifCh <- resnot
-				} else {
-					lightLog.WithField("error", d.err).Error("Error receiving message")
-					if d.err == io.EOF {
-						log.Error("Connection closed")
-						err = l.Connect()
-						if err != nil {
-							lightLog.WithField("error", d.err).Error("Error reconnecting")
-							goto exit
+					if !ln.push(l, resnot) {
+						goto exit
+					}
+				} else if d.gen == l.connGen.Load() {
+					// Errors from connections already replaced are ignored
+					lightLog.WithField("error", d.err).Errorf("Error receiving message")
+					if errors.Is(d.err, io.EOF) {
+						lightLog.Errorf("Connection closed")
+					}
+					if !l.reconnect(ln.stop) {
+						select {
+						case <-ln.stop:
+						default:
+							lerr = d.err
 						}
+						goto exit
 					}
+					// Needs this loop to get the result
+					go l.resync()
 				}
 			}
 		}
 	exit:
 		return
-	}(l.Conn)
+	}()
 
-	return done, nil
+	return ln, nil
 }
 
-func (l *Light) processNotification(n *Notification) error {
-	mapNotificationS := map[string]*string{
-		"name":          &l.Name,
-		"id":            &l.ID,
-		"model":         &l.Model,
-		"power":         &l.Power,
-		"cache-control": &l.CacheControl,
-	}
-	mapNotificationI := map[string]*int{
-		"fw_ver":     &l.FW,
-		"bright":     &l.Bright,
-		"color_mode": &l.ColorMode,
-		"ct":         &l.CT,
-		"rgb":        &l.RGB,
-		"hue":        &l.Hue,
-		"sat":        &l.Sat,
+// rearmRefresh postpones the periodic refresh of a listened
+// light, as something was just heard from it
+func (l *Light) rearmRefresh() {
+	select {
+	case l.resetRefresh <- struct{}{}:
+	default:
 	}
+}
+
+// processNotification updates light with n returning false
+// if n did not change anything
+func (l *Light) processNotification(n *Notification) bool {
+	mapNotificationS, mapNotificationI := l.propFields()
 
 	if n.Method == "props" {
-		// FIXME: JSON dedicated struct for params would be better ?
+		l.quietRefreshes = 0
+		var changes []PropertyEvent
 		for k, v := range mapNotificationI {
-			if n.Params[k] != nil {
-				*v = int(n.Params[k].(float64))
+			if i, ok := paramInt(n.Params[k]); ok {
+				old := *v
+				*v = i
+				if old != *v {
+					changes = append(changes, l.propertyEvent(k, strconv.Itoa(old), strconv.Itoa(*v)))
+				}
 			}
 		}
 		for k, v := range mapNotificationS {
-			if n.Params[k] != nil {
-				str := (n.Params[k]).(string)
-				if str != "" {
+			if str, ok := n.Params[k].(string); ok {
+				if str != "" && str != *v {
+					changes = append(changes, l.propertyEvent(k, *v, str))
 					*v = str
 				}
 			}
 		}
+		l.notifyChanges(changes)
+		if len(changes) == 0 {
+			return false
+		}
+		l.Flowing = l.flowing == 1
+		l.CurrentFlow = runningFlow(l.flowing, l.FlowParams)
+		// Notified values are as fresh as a get_prop
+		vals := make(map[string]string, len(n.Params))
+		for k, v := range n.Params {
+			vals[k] = propString(v)
+		}
+		l.cacheProps(vals)
+	} else {
+		l.log().WithField("method", n.Method).Warnf("Unknown notification")
+		l.emit(Event{Kind: EventUnknownNotification, Notification: n})
+		l.anomaly(Anomaly{Notification: n})
 	}
-	return nil
+	return true
 }
 
 func (l *Light) processResult(r *Result) error {
-	if l.Calls[int32(r.ID)] != nil {
-		delete(l.Calls, int32(r.ID))
-		l.Status = ONLINE
-		l.ResC <- r
+	if l.complete(r) {
+		l.counters.matched.Add(1)
+		l.emit(Event{Kind: EventResult, Result: r})
+		l.setStatus(ONLINE)
+		// Legacy consumers of ResC get results if they are reading
+		select {
+		case l.ResC <- r:
+		default:
+		}
 	} else {
-		log.WithField("ID", l.ID).Warn("Reply received to unknown request:", r.ID)
+		l.counters.unmatched.Add(1)
+		l.log().Warnf("Reply received to unknown request: %d", r.ID)
+		l.emit(Event{Kind: EventUnmatched, Result: r})
+		l.anomaly(Anomaly{Result: r})
 	}
 	return nil
 }
 
 // SendCommand sends "comm" command to a light with "params" parameters
-// returning the request ID for tracking results
-func (l *Light) SendCommand(comm string, params ...interface{}) (int32, error) {
-	lightLog := log.WithFields(log.Fields{
-		"ID":      l.ID,
-		"address": l.Address,
-		"name":    l.Name,
-	})
+// returning the request ID for tracking results. With an offline queue
+// configured state-changing commands are queued while disconnected
+func (l *Light) SendCommand(comm string, params ...interface{}) (id int32, err error) {
+	_, sp := l.startSpan(context.Background(), "yeelight.command", Attr{"yeelight.method", comm})
+	defer func() {
+		sp.SetAttrs(Attr{"yeelight.request", int(id)})
+		endSpan(sp, err)
+	}()
 	if !l.Support[comm] {
 		return -1, errCommandNotSupported
 	}
-	if l.Conn == nil {
-		return -1, errNotConnected
-	}
 	cmd := &Command{
-		ID:     atomic.LoadInt32(&l.ReqCount),
 		Method: comm,
 		Params: params,
 	}
-	jCmd, err := json.Marshal(cmd)
-	if err != nil {
-		lightLog.Error("Error formating JSON")
+	if err = l.validate(cmd); err != nil {
+		return -1, err
+	}
+	cmd.ID = atomic.AddInt32(&l.ReqCount, 1) - 1
+	l.applyCurve(cmd)
+	l.calibrate(cmd)
+	l.rewrite(cmd)
+	if l.transport == nil || l.getStatus() == OFFLINE {
+		if l.enqueue(cmd) {
+			return cmd.ID, nil
+		}
+		if l.transport == nil {
+			return -1, errNotConnected
+		}
+	}
+	if err = l.write(cmd); err != nil {
+		if l.enqueue(cmd) {
+			return cmd.ID, nil
+		}
 		return -1, err
 	}
-	lightLog.Debug("Sending: ", string(jCmd))
+	return cmd.ID, nil
+}
 
-	jCmd = bytes.Join([][]byte{jCmd, endOfCommand}, nil)
-	_, err = l.Conn.Write(jCmd)
+// send writes cmd to the light tracking it for its result
+func (l *Light) send(cmd *Command) error {
+	lightLog := l.log()
+	buf := cmdBufPool.Get().(*[]byte)
+	defer cmdBufPool.Put(buf)
+	jCmd, err := appendCommand((*buf)[:0], cmd)
+	*buf = jCmd
 	if err != nil {
-		lightLog.WithField("error", err).Error("Error sending")
-		log.Error("Trying reconnect")
-		err = l.Connect()
+		lightLog.Errorf("Error formating JSON")
+		return err
+	}
+	lightLog.Debugf("Sending: %s", jCmd[:len(jCmd)-len(endOfCommand)])
+
+	if music := l.musicConn(); music != nil && viaMusic(cmd.Method) {
+		// Music mode has no results to track
+		l.writeDeadline(music)
+		l.wmu.Lock()
+		_, err = music.Write(jCmd)
+		l.wmu.Unlock()
 		if err != nil {
-			lightLog.WithField("error", err).Error("Error reconnecting")
+			lightLog.WithField("error", err).Warnf("Music mode lost")
+			l.StopMusic()
+			return fmt.Errorf("send %s: %w", cmd.Method, err)
 		}
-		return -1, err
+		l.counters.sent.Add(1)
+		l.countSent()
+		l.emit(Event{Kind: EventCommandSent, Command: cmd})
+		return nil
+	}
+	// Tracked before writing as the result may arrive right away
+	l.track(cmd)
+	l.emit(Event{Kind: EventCommandSent, Command: cmd})
+	l.wmu.Lock()
+	err = l.transport.Send(jCmd)
+	l.wmu.Unlock()
+	if err != nil {
+		l.untrack(cmd.ID)
+		lightLog.WithField("error", err).Errorf("Error sending")
+		err = fmt.Errorf("send %s: %w", cmd.Method, err)
+		lightLog.Errorf("Trying reconnect")
+		if cerr := l.Connect(); cerr != nil {
+			lightLog.WithField("error", cerr).Errorf("Error reconnecting")
+		}
+		return err
+	}
+	l.counters.sent.Add(1)
+	l.countSent()
+	return nil
+}
+
+// writeDeadline bounds the next write on conn
+func (l *Light) writeDeadline(conn net.Conn) {
+	if l.cfg.writeTimeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(l.cfg.writeTimeout))
 	}
-	l.Calls[cmd.ID] = cmd
-	return (atomic.AddInt32(&l.ReqCount, 1) - 1), nil
 }
 
-// WaitResult waits timeout seconds for a result on a request with res ID
+// WaitResult waits timeout seconds for a result on a request with res ID,
+// nil is returned on timeout.
+//
+// Deprecated: use WaitResultCtx, which reports why no result came
 func (l *Light) WaitResult(res int32, timeout int) *Result {
+	return l.waitResult(res, time.Duration(timeout)*time.Second)
+}
+
+func (l *Light) waitResult(res int32, timeout time.Duration) *Result {
+	f := l.future(res)
+	if f == nil {
+		l.log().Warnf("Waiting unknown request: %d", res)
+		return nil
+	}
+	_, sp := l.startSpan(context.Background(), "yeelight.wait", l.requestAttrs(res)...)
+	defer sp.End()
 	select {
-	case r := <-l.ResC:
-		if int32(r.ID) == res {
-			l.Status = ONLINE
-			return r
+	case r := <-f.result:
+		l.untrack(res)
+		if r.Error == nil || r.Error.Code != errCodeTimeout {
+			l.setStatus(ONLINE)
 		}
-		log.WithField("ID", l.ID).Warn("Result ID unexpected: ", r.ID)
-	case <-time.After(time.Duration(timeout) * time.Second):
+		sp.SetAttrs(resultAttrs(r)...)
+		return r
+	case <-l.clock().After(timeout):
+		sp.SetAttrs(resultAttrs(nil)...)
 		return nil
 	}
-	return nil
 }
 
 // Message gets light messages
 func (l *Light) Message() (string, error) {
-	if l.Conn == nil {
-		return "", errNotConnected
+	var raw json.RawMessage
+	if err := l.read(&raw); err != nil {
+		return "", err
 	}
-	resp, err := l.Reader.ReadString('\n')
+	return string(raw), nil
+}
 
-	if err != nil {
-		return "", err
+// readMessage decodes the next result or notification from light
+func (l *Light) readMessage() (*ResultNotification, error) {
+	resnot := new(ResultNotification)
+	if err := l.read(resnot); err != nil {
+		return nil, err
+	}
+	return resnot, nil
+}
+
+// read decodes the next JSON object sent by light into v, several
+// objects per packet and objects split across packets are fine
+func (l *Light) read(v interface{}) error {
+	if l.transport == nil {
+		return errNotConnected
+	}
+	err := l.transport.Receive(v)
+	var typeErr *json.UnmarshalTypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return err
 	}
-	l.LastSeen = time.Now().Unix()
-	l.refresh = time.After(refreshPeriod)
-	return resp, nil
+	// Something arrived even if it didn't fit v
+	l.seen()
+	l.rearmRefresh()
+	return err
 }
 
 // Toggle toogle light's power on/off
@@ -431,84 +641,46 @@ func (l *Light) Toggle() (int32, error) {
 	return l.SendCommand("toggle", "")
 }
 
-// SetPower set light's power with effect of duration
-func (l *Light) SetPower(power bool, effect int, duration int) (int32, error) {
-	var str, p string
+// SetPower set light's power with effect
+func (l *Light) SetPower(power bool, effect Effect) (int32, error) {
+	p := "off"
 	if power {
 		p = "on"
-	} else {
-		p = "off"
-	}
-	if duration > 0 {
-		str = "smooth"
-	} else {
-		str = "sudden"
-		duration = 0
 	}
-	return l.SendCommand("set_bright", p, str, duration)
+	return l.sendEffect("set_power", effect, p)
 }
 
-// SetBrightness set light's brightness with effect of duration
-func (l *Light) SetBrightness(brightness int, duration int) (int32, error) {
-	var str string
-
-	if duration > 0 {
-		str = "smooth"
-	} else {
-		str = "sudden"
-		duration = 0
-	}
-	return l.SendCommand("set_bright", brightness, str, duration)
+// SetBrightness set light's brightness with effect
+func (l *Light) SetBrightness(brightness int, effect Effect) (int32, error) {
+	return l.sendEffect("set_bright", effect, brightness)
 }
 
-// SetTemperature set light's color temperature with effect of duration
-func (l *Light) SetTemperature(temp int, duration int) (int32, error) {
-	var str string
-
-	if duration > 0 {
-		str = "smooth"
-	} else {
-		str = "sudden"
-		duration = 0
-	}
-	return l.SendCommand("set_ct_abx", temp, str, duration)
+// SetTemperature set light's color temperature with effect
+func (l *Light) SetTemperature(temp int, effect Effect) (int32, error) {
+	return l.sendEffect("set_ct_abx", effect, temp)
 }
 
-// SetRGB set light's color in RGB format with effect of duration
-func (l *Light) SetRGB(rgb uint32, duration int) (int32, error) {
-	var str string
-
-	if rgb > 0xffffff {
-		return 0, errInvalidParam
-	}
-	if duration > 0 {
-		str = "smooth"
-	} else {
-		str = "sudden"
-		duration = 0
-	}
-	return l.SendCommand("set_rgb", rgb, str, duration)
+// SetRGB set light's color in RGB format with effect
+func (l *Light) SetRGB(rgb uint32, effect Effect) (int32, error) {
+	return l.sendEffect("set_rgb", effect, rgb)
 }
 
-// SetHSV set light's color in HSV format with effect of duration
-func (l *Light) SetHSV(hsv uint16, sat uint8, duration int) (int32, error) {
-	var str string
+// SetHSV set light's color in HSV format with effect
+func (l *Light) SetHSV(hsv uint16, sat uint8, effect Effect) (int32, error) {
+	return l.sendEffect("set_hsv", effect, hsv, sat)
+}
 
-	if sat > 100 || hsv > 359 {
-		return 0, errInvalidParam
-	}
-	if duration > 0 {
-		str = "smooth"
-	} else {
-		str = "sudden"
-		duration = 0
+// sendEffect sends comm with params followed by effect's
+func (l *Light) sendEffect(comm string, effect Effect, params ...interface{}) (int32, error) {
+	ep, err := effect.Params()
+	if err != nil {
+		return 0, err
 	}
-	return l.SendCommand("set_hsv", hsv, sat, str, duration)
+	return l.SendCommand(comm, append(params, ep...)...)
 }
 
 // SetName set light's name
-func (l *Light) SetName(name string, duration int) (int32, error) {
-
+func (l *Light) SetName(name string) (int32, error) {
 	r, err := l.SendCommand("set_name", name)
 	if err == nil {
 		l.Name = name
@@ -520,3 +692,9 @@ func (l *Light) SetName(name string, duration int) (int32, error) {
 func (l *Light) GetProp(props ...interface{}) (int32, error) {
 	return l.SendCommand("get_prop", props...)
 }
+
+// Ping sends the cheapest probe supported by light's model and
+// firmware, the light is alive if a result arrives for the request
+func (l *Light) Ping() (int32, error) {
+	return l.SendCommand("get_prop", l.ModelSpec().pingParams(l.FW)...)
+}
@@ -0,0 +1,281 @@
+// Package yeelighttest provides a fake light for integration tests of
+// the yeelight package and applications built on it
+package yeelighttest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+// DefaultSupport are the methods announced by a Bulb by default
+var DefaultSupport = []string{
+	"get_prop", "set_default", "set_power", "toggle", "set_bright",
+	"start_cf", "stop_cf", "set_scene", "cron_add", "cron_get",
+	"cron_del", "set_ct_abx", "set_rgb", "set_hsv", "set_adjust",
+	"adjust_bright", "adjust_ct", "adjust_color", "set_music", "set_name",
+}
+
+// Bulb is a fake light speaking the JSON protocol on a local port.
+// Its fields must not be changed once started
+type Bulb struct {
+	ID      string
+	Model   string
+	FW      int
+	Support []string
+	// Quota is the number of commands accepted per minute,
+	// zero accepts them all
+	Quota int
+
+	mu      sync.Mutex
+	props   map[string]string
+	fail    map[string]*yeelight.Error
+	sent    []time.Time
+	ln      net.Listener
+	conns   map[net.Conn]bool
+	udp     net.PacketConn
+	wg      sync.WaitGroup
+	started bool
+}
+
+// NewBulb returns a started Bulb, on and white
+func NewBulb() *Bulb {
+	b := NewUnstartedBulb()
+	b.Start()
+	return b
+}
+
+// NewUnstartedBulb returns a Bulb to be configured before Start
+func NewUnstartedBulb() *Bulb {
+	return &Bulb{
+		ID:      "0x0000000000000001",
+		Model:   "color",
+		FW:      18,
+		Support: DefaultSupport,
+		props: map[string]string{
+			"power":      "on",
+			"bright":     "100",
+			"ct":         "4000",
+			"rgb":        "16777215",
+			"hue":        "0",
+			"sat":        "0",
+			"color_mode": "2",
+			"name":       "",
+		},
+		fail:  make(map[string]*yeelight.Error),
+		conns: make(map[net.Conn]bool),
+	}
+}
+
+// Start starts accepting connections on a loopback port
+func (b *Bulb) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		panic("yeelighttest: Bulb already started")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("yeelighttest: failed to listen: %v", err))
+	}
+	b.ln = ln
+	b.started = true
+	b.wg.Add(1)
+	go b.accept()
+}
+
+// Close stops the bulb closing all its connections
+func (b *Bulb) Close() {
+	b.mu.Lock()
+	if b.ln != nil {
+		b.ln.Close()
+	}
+	if b.udp != nil {
+		b.udp.Close()
+	}
+	for c := range b.conns {
+		c.Close()
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+// Drop closes the connections of the clients like a light briefly
+// losing its network, the bulb keeps accepting new ones. props are
+// changed meanwhile so clients only learn them by asking
+func (b *Bulb) Drop(props map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, v := range props {
+		b.props[k] = v
+	}
+	for c := range b.conns {
+		c.Close()
+	}
+}
+
+// Conns returns the number of open control connections
+func (b *Bulb) Conns() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, control := range b.conns {
+		if control {
+			n++
+		}
+	}
+	return n
+}
+
+// Addr returns the address of bulb's control port
+func (b *Bulb) Addr() string {
+	return b.ln.Addr().String()
+}
+
+// Header returns the SSDP headers bulb announces itself with
+func (b *Bulb) Header() http.Header {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := make(http.Header)
+	h.Set("Cache-Control", "max-age=3600")
+	h.Set("Location", "yeelight://"+b.Addr())
+	h.Set("Server", "POSIX UPnP/1.0 YGLC/1")
+	h.Set("Id", b.ID)
+	h.Set("Model", b.Model)
+	h.Set("Fw_ver", strconv.Itoa(b.FW))
+	h.Set("Support", strings.Join(b.Support, " "))
+	for _, p := range []string{"power", "bright", "color_mode", "ct", "rgb", "hue", "sat", "name"} {
+		h.Set(p, b.props[p])
+	}
+	return h
+}
+
+// Light returns a yeelight.Light for bulb as found by SSDP
+func (b *Bulb) Light() (*yeelight.Light, error) {
+	return yeelight.Parse(b.Header())
+}
+
+// Prop returns the value of property name, "" if unknown
+func (b *Bulb) Prop(name string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.props[name]
+}
+
+// SetProp changes property name as if done from the
+// outside, connected clients are notified
+func (b *Bulb) SetProp(name, value string) {
+	b.mu.Lock()
+	b.props[name] = value
+	b.mu.Unlock()
+	b.notify(map[string]string{name: value})
+}
+
+// FailNext makes the next call to method fail with code and message
+func (b *Bulb) FailNext(method string, code int, message string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fail[method] = &yeelight.Error{Code: code, Message: message}
+}
+
+func (b *Bulb) accept() {
+	defer b.wg.Done()
+	for {
+		c, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns[c] = true
+		b.mu.Unlock()
+		b.wg.Add(1)
+		go b.serve(c, true)
+	}
+}
+
+// serve runs the commands read from c, replies are
+// only sent on control connections, not in music mode
+func (b *Bulb) serve(c net.Conn, reply bool) {
+	defer b.wg.Done()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+		c.Close()
+	}()
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var cmd command
+		res := &result{}
+		if err := json.Unmarshal(line, &cmd); err != nil {
+			res.Error = &yeelight.Error{Code: -1, Message: "invalid command"}
+		} else {
+			res.ID = cmd.ID
+			res.Result, res.Error = b.run(cmd.Method, cmd.Params, reply)
+		}
+		if reply {
+			b.write(c, res)
+		}
+	}
+}
+
+func (b *Bulb) write(c net.Conn, v interface{}) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	c.Write(append(j, '\r', '\n'))
+}
+
+// notify sends a props notification to all control connections
+func (b *Bulb) notify(props map[string]string) {
+	n := notification{Method: "props", Params: make(map[string]interface{}, len(props))}
+	for k, v := range props {
+		// Lights send numeric properties as numbers
+		if i, err := strconv.Atoi(v); err == nil && k != "name" {
+			n.Params[k] = i
+		} else {
+			n.Params[k] = v
+		}
+	}
+	b.broadcast(n)
+}
+
+// SendResult sends an unsolicited result for request id to the
+// connected clients, like lights answering late or twice
+func (b *Bulb) SendResult(id int, values ...interface{}) {
+	b.broadcast(result{ID: id, Result: values})
+}
+
+// SendNotification sends a notification of method to the connected
+// clients, real lights only send "props"
+func (b *Bulb) SendNotification(method string, params map[string]interface{}) {
+	b.broadcast(notification{Method: method, Params: params})
+}
+
+// broadcast writes v to all control connections
+func (b *Bulb) broadcast(v interface{}) {
+	b.mu.Lock()
+	conns := make([]net.Conn, 0, len(b.conns))
+	for c, control := range b.conns {
+		if control {
+			conns = append(conns, c)
+		}
+	}
+	b.mu.Unlock()
+	for _, c := range conns {
+		b.write(c, v)
+	}
+}
@@ -0,0 +1,121 @@
+package yeelighttest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+// Clock is a yeelight.Clock that only moves on Advance,
+// pass it with yeelight.WithClock
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is a pending After or a Ticker
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewClock returns a Clock set at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now implements yeelight.Clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After implements yeelight.Clock
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+// NewTicker implements yeelight.Clock
+func (c *Clock) NewTicker(d time.Duration) yeelight.Ticker {
+	if d <= 0 {
+		panic("yeelighttest: non-positive interval for NewTicker")
+	}
+	return &ticker{clock: c, w: c.add(d, d)}
+}
+
+func (c *Clock) add(d, period time.Duration) *waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &waiter{at: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.c <- c.now
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (c *Clock) remove(w *waiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, o := range c.waiters {
+		if o == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance moves the clock d forward firing the timers and tickers
+// due in order, ticks are dropped if the previous one is unread
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	end := c.now.Add(d)
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].at.Before(c.waiters[j].at)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].at.After(end) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.at
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+	c.now = end
+}
+
+// Waiters returns the number of timers and tickers pending, tests
+// can poll it to know the code under test is waiting on the clock
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+type ticker struct {
+	clock *Clock
+	w     *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t *ticker) Stop() {
+	t.clock.remove(t.w)
+}
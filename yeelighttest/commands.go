@@ -0,0 +1,244 @@
+package yeelighttest
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pulento/yeelight"
+)
+
+// Messages on the wire, Result and Notification of the
+// yeelight package carry fields lights don't send
+type command struct {
+	ID     int           `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+type result struct {
+	ID     int             `json:"id"`
+	Result []interface{}   `json:"result,omitempty"`
+	Error  *yeelight.Error `json:"error,omitempty"`
+}
+
+type notification struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// Errors answered the way real lights do
+var (
+	errUnsupported = &yeelight.Error{Code: -1, Message: "method not supported"}
+	errParams      = &yeelight.Error{Code: -1, Message: "invalid params"}
+	errQuota       = &yeelight.Error{Code: -1, Message: "client quota exceeded"}
+)
+
+var ok = []interface{}{"ok"}
+
+// run executes method returning its result or error, only commands
+// of control connections count against the quota
+func (b *Bulb) run(method string, params []interface{}, control bool) ([]interface{}, *yeelight.Error) {
+	b.mu.Lock()
+	if e := b.fail[method]; e != nil {
+		delete(b.fail, method)
+		b.mu.Unlock()
+		return nil, e
+	}
+	if !b.supports(method) {
+		b.mu.Unlock()
+		return nil, errUnsupported
+	}
+	if control && !b.allow() {
+		b.mu.Unlock()
+		return nil, errQuota
+	}
+	b.mu.Unlock()
+
+	switch method {
+	case "get_prop":
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		vals := make([]interface{}, len(params))
+		for i, p := range params {
+			name, _ := p.(string)
+			vals[i] = b.props[name]
+		}
+		return vals, nil
+	case "set_power":
+		p, valid := str(params, 0)
+		if !valid || (p != "on" && p != "off") {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"power": p})
+	case "toggle":
+		p := "on"
+		if b.Prop("power") == "on" {
+			p = "off"
+		}
+		return b.set(map[string]string{"power": p})
+	case "set_bright":
+		v, valid := num(params, 0, 1, 100)
+		if !valid {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"bright": v})
+	case "set_ct_abx":
+		v, valid := num(params, 0, 1700, 6500)
+		if !valid {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"ct": v, "color_mode": "2"})
+	case "set_rgb":
+		v, valid := num(params, 0, 0, 0xffffff)
+		if !valid {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"rgb": v, "color_mode": "1"})
+	case "set_hsv":
+		hue, hok := num(params, 0, 0, 359)
+		sat, sok := num(params, 1, 0, 100)
+		if !hok || !sok {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"hue": hue, "sat": sat, "color_mode": "3"})
+	case "set_name":
+		name, valid := str(params, 0)
+		if !valid {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"name": name})
+	case "set_scene":
+		return b.scene(params)
+	case "set_music":
+		return b.music(params)
+	}
+	return ok, nil
+}
+
+// set changes props notifying connected clients
+func (b *Bulb) set(props map[string]string) ([]interface{}, *yeelight.Error) {
+	b.mu.Lock()
+	for k, v := range props {
+		b.props[k] = v
+	}
+	b.mu.Unlock()
+	b.notify(props)
+	return ok, nil
+}
+
+// scene sets color or temperature and brightness at once,
+// other scene classes are accepted without changes
+func (b *Bulb) scene(params []interface{}) ([]interface{}, *yeelight.Error) {
+	class, valid := str(params, 0)
+	if !valid {
+		return nil, errParams
+	}
+	switch class {
+	case "color":
+		rgb, cok := num(params, 1, 0, 0xffffff)
+		bright, bok := num(params, 2, 1, 100)
+		if !cok || !bok {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"power": "on", "rgb": rgb, "bright": bright, "color_mode": "1"})
+	case "hsv":
+		hue, hok := num(params, 1, 0, 359)
+		sat, sok := num(params, 2, 0, 100)
+		bright, bok := num(params, 3, 1, 100)
+		if !hok || !sok || !bok {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"power": "on", "hue": hue, "sat": sat, "bright": bright, "color_mode": "3"})
+	case "ct":
+		ct, cok := num(params, 1, 1700, 6500)
+		bright, bok := num(params, 2, 1, 100)
+		if !cok || !bok {
+			return nil, errParams
+		}
+		return b.set(map[string]string{"power": "on", "ct": ct, "bright": bright, "color_mode": "2"})
+	}
+	return ok, nil
+}
+
+// music connects back to the client for set_music 1, commands
+// received on that connection are run without replies
+func (b *Bulb) music(params []interface{}) ([]interface{}, *yeelight.Error) {
+	action, valid := num(params, 0, 0, 1)
+	if !valid {
+		return nil, errParams
+	}
+	if action == "0" {
+		b.mu.Lock()
+		b.props["music_on"] = "0"
+		b.mu.Unlock()
+		return ok, nil
+	}
+	host, hok := str(params, 1)
+	port, pok := num(params, 2, 1, 65535)
+	if !hok || !pok {
+		return nil, errParams
+	}
+	c, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), time.Second)
+	if err != nil {
+		return nil, &yeelight.Error{Code: -1, Message: "cannot connect to music server"}
+	}
+	b.mu.Lock()
+	b.conns[c] = false
+	b.props["music_on"] = "1"
+	b.mu.Unlock()
+	b.wg.Add(1)
+	go b.serve(c, false)
+	return ok, nil
+}
+
+// supports returns true if method is announced, b.mu must be held
+func (b *Bulb) supports(method string) bool {
+	for _, s := range b.Support {
+		if s == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allow accounts a command against the quota, b.mu must be held
+func (b *Bulb) allow() bool {
+	if b.Quota <= 0 {
+		return true
+	}
+	now := time.Now()
+	recent := b.sent[:0]
+	for _, t := range b.sent {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	b.sent = recent
+	if len(b.sent) >= b.Quota {
+		return false
+	}
+	b.sent = append(b.sent, now)
+	return true
+}
+
+// str returns params[i] if it is a string
+func str(params []interface{}, i int) (string, bool) {
+	if i >= len(params) {
+		return "", false
+	}
+	s, ok := params[i].(string)
+	return s, ok
+}
+
+// num returns params[i] formatted if it is an integer in [min, max]
+func num(params []interface{}, i int, min, max int) (string, bool) {
+	if i >= len(params) {
+		return "", false
+	}
+	f, ok := params[i].(float64)
+	if !ok || f != float64(int(f)) || int(f) < min || int(f) > max {
+		return "", false
+	}
+	return strconv.Itoa(int(f)), true
+}
@@ -0,0 +1,53 @@
+package yeelighttest
+
+import (
+	"github.com/pulento/yeelight"
+	"github.com/pulento/yeelight/internal/testhooks"
+)
+
+// Internals is a snapshot of a light's internal queues, meant for
+// deterministic tests of applications built on yeelight
+type Internals struct {
+	// PendingCalls are the commands waiting for a result, by ID
+	PendingCalls []yeelight.Command `json:"pending_calls"`
+	// QueuedCommands are waiting for the light to reconnect
+	QueuedCommands []yeelight.Command `json:"queued_commands"`
+	// Backlogs is the number of undelivered events per subscription
+	Backlogs map[string]int `json:"backlogs"`
+	// Dropped is the number of results and notifications
+	// discarded by the overflow policy
+	Dropped uint64 `json:"dropped"`
+	Frozen  bool   `json:"frozen"`
+}
+
+// Inspect returns a snapshot of l's internal queues
+func Inspect(l *yeelight.Light) Internals {
+	s := testhooks.Internals(l)
+	return Internals{
+		PendingCalls:   commands(s.PendingCalls),
+		QueuedCommands: commands(s.QueuedCommands),
+		Backlogs:       s.Backlogs,
+		Dropped:        s.Dropped,
+		Frozen:         s.Frozen,
+	}
+}
+
+// Freeze stops expiring l's pending calls and flushing its queued
+// commands so tests can inspect them, Thaw resumes normal operation
+func Freeze(l *yeelight.Light) {
+	testhooks.SetFrozen(l, true)
+}
+
+// Thaw resumes what Freeze stopped flushing queued commands
+// if the light is connected
+func Thaw(l *yeelight.Light) {
+	testhooks.SetFrozen(l, false)
+}
+
+func commands(cs []testhooks.Command) []yeelight.Command {
+	out := make([]yeelight.Command, len(cs))
+	for i, c := range cs {
+		out[i] = yeelight.Command(c)
+	}
+	return out
+}
@@ -0,0 +1,132 @@
+package yeelighttest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pulento/yeelight"
+)
+
+// Replay is a yeelight.Transport feeding back frames recorded with
+// yeelight.WithRecorder. Received frames are delivered once the
+// commands recorded before them were sent, results get the ID of
+// the command actually sent
+type Replay struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	frames []yeelight.Frame
+	next   int
+	ids    map[int]int
+	closed bool
+}
+
+// NewReplay reads the frames recorded in r, frames of other lights
+// are skipped if addr is not empty
+func NewReplay(r io.Reader, addr string) (*Replay, error) {
+	rp := &Replay{ids: make(map[int]int)}
+	rp.cond = sync.NewCond(&rp.mu)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var f yeelight.Frame
+		if err := json.Unmarshal(sc.Bytes(), &f); err != nil {
+			return nil, err
+		}
+		if addr == "" || f.Addr == addr {
+			rp.frames = append(rp.frames, f)
+		}
+	}
+	return rp, sc.Err()
+}
+
+// Dial implements yeelight.Transport
+func (rp *Replay) Dial(addr string) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.closed = false
+	return nil
+}
+
+// Send implements yeelight.Transport, it fails if b is not the
+// command recorded next
+func (rp *Replay) Send(b []byte) error {
+	var sent command
+	if err := json.Unmarshal(b, &sent); err != nil {
+		return err
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.next >= len(rp.frames) || rp.frames[rp.next].Dir != yeelight.FrameSent {
+		return fmt.Errorf("replay: unexpected command %s", sent.Method)
+	}
+	var want command
+	json.Unmarshal(rp.frames[rp.next].Data, &want)
+	if want.Method != sent.Method {
+		return fmt.Errorf("replay: sent %s, recorded %s", sent.Method, want.Method)
+	}
+	rp.ids[want.ID] = sent.ID
+	rp.next++
+	rp.cond.Broadcast()
+	return nil
+}
+
+// Receive implements yeelight.Transport, once all frames were
+// replayed it blocks until Close
+func (rp *Replay) Receive(v interface{}) error {
+	rp.mu.Lock()
+	for !rp.closed && (rp.next >= len(rp.frames) || rp.frames[rp.next].Dir != yeelight.FrameReceived) {
+		rp.cond.Wait()
+	}
+	if rp.closed {
+		rp.mu.Unlock()
+		return io.EOF
+	}
+	data := rp.frames[rp.next].Data
+	rp.next++
+	rp.cond.Broadcast()
+	data = rp.rewriteID(data)
+	rp.mu.Unlock()
+	return json.Unmarshal(data, v)
+}
+
+// Close implements yeelight.Transport
+func (rp *Replay) Close() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.closed = true
+	rp.cond.Broadcast()
+	return nil
+}
+
+// Done returns true once all frames were replayed
+func (rp *Replay) Done() bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.next >= len(rp.frames)
+}
+
+// rewriteID gives a recorded result the ID of the command sent
+// in its place, rp.mu must be held
+func (rp *Replay) rewriteID(data json.RawMessage) json.RawMessage {
+	var m map[string]json.RawMessage
+	if json.Unmarshal(data, &m) != nil || m["id"] == nil {
+		return data
+	}
+	var id int
+	if json.Unmarshal(m["id"], &id) != nil {
+		return data
+	}
+	sent, ok := rp.ids[id]
+	if !ok || sent == id {
+		return data
+	}
+	m["id"], _ = json.Marshal(sent)
+	out, err := json.Marshal(m)
+	if err != nil {
+		return data
+	}
+	return out
+}
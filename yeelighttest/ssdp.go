@@ -0,0 +1,44 @@
+package yeelighttest
+
+import (
+	"bytes"
+	"net"
+	"strings"
+)
+
+// ListenSSDP answers SSDP searches for lights received on addr,
+// a UDP address like "127.0.0.1:0", returning the address bound.
+// Point the searches there with yeelight.WithMulticastAddress
+func (b *Bulb) ListenSSDP(addr string) (string, error) {
+	pc, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	b.udp = pc
+	b.mu.Unlock()
+	b.wg.Add(1)
+	go b.answer(pc)
+	return pc.LocalAddr().String(), nil
+}
+
+// answer replies M-SEARCH requests for lights until pc is closed
+func (b *Bulb) answer(pc net.PacketConn) {
+	defer b.wg.Done()
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req := buf[:n]
+		if !bytes.HasPrefix(req, []byte("M-SEARCH")) || !bytes.Contains(req, []byte("wifi_bulb")) {
+			continue
+		}
+		var resp strings.Builder
+		resp.WriteString("HTTP/1.1 200 OK\r\n")
+		b.Header().Write(&resp)
+		resp.WriteString("\r\n")
+		pc.WriteTo([]byte(resp.String()), from)
+	}
+}
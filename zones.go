@@ -0,0 +1,206 @@
+package yeelight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Kinds of zones, any other name can be used
+const (
+	ZoneHouse = "house"
+	ZoneFloor = "floor"
+	ZoneRoom  = "room"
+)
+
+// Zone is a node of a home topology like house → floor → room,
+// layered over groups so flat groups scale to bigger homes. Commands
+// sent to a zone cascade to the lights of its groups and subzones
+type Zone struct {
+	Name     string
+	Kind     string
+	Groups   []*Group
+	Children []*Zone
+	parent   *Zone
+}
+
+// NewZone returns an empty zone name of kind
+func NewZone(name, kind string) *Zone {
+	return &Zone{Name: name, Kind: kind}
+}
+
+// Add adds subzones to z
+func (z *Zone) Add(children ...*Zone) *Zone {
+	for _, c := range children {
+		c.parent = z
+	}
+	z.Children = append(z.Children, children...)
+	return z
+}
+
+// AddGroup adds groups of lights to z
+func (z *Zone) AddGroup(groups ...*Group) *Zone {
+	z.Groups = append(z.Groups, groups...)
+	return z
+}
+
+// Parent returns the zone containing z, nil for the root
+func (z *Zone) Parent() *Zone {
+	return z.parent
+}
+
+// Path returns the names of the zones from the root to z
+func (z *Zone) Path() []string {
+	if z.parent == nil {
+		return []string{z.Name}
+	}
+	return append(z.parent.Path(), z.Name)
+}
+
+// Walk calls f for z and its subzones depth first until f returns false
+func (z *Zone) Walk(f func(*Zone) bool) bool {
+	if !f(z) {
+		return false
+	}
+	for _, c := range z.Children {
+		if !c.Walk(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find returns the zone name under z, z included, or nil
+func (z *Zone) Find(name string) *Zone {
+	var found *Zone
+	z.Walk(func(c *Zone) bool {
+		if c.Name == name {
+			found = c
+		}
+		return found == nil
+	})
+	return found
+}
+
+// Lights returns the lights of z and its subzones, once each
+func (z *Zone) Lights() []*Light {
+	var lights []*Light
+	seen := make(map[*Light]bool)
+	z.Walk(func(c *Zone) bool {
+		for _, g := range c.Groups {
+			for _, l := range g.Lights {
+				if !seen[l] {
+					seen[l] = true
+					lights = append(lights, l)
+				}
+			}
+		}
+		return true
+	})
+	return lights
+}
+
+// ForEach runs f on every light of the zone, see ForEach
+func (z *Zone) ForEach(ctx context.Context, workers int, f func(ctx context.Context, l *Light) error) error {
+	return ForEach(ctx, z.Lights(), workers, f)
+}
+
+// Broadcast calls method on every light of the zone, see Broadcast
+func (z *Zone) Broadcast(ctx context.Context, workers int, method string, params ...interface{}) ([]BatchResult, error) {
+	return Broadcast(ctx, z.Lights(), workers, method, params...)
+}
+
+// On turns on every light of the zone
+func (z *Zone) On(ctx context.Context) error {
+	_, err := z.Broadcast(ctx, 0, "set_power", "on", "smooth", defaultZoneFade)
+	return err
+}
+
+// Off turns off every light of the zone
+func (z *Zone) Off(ctx context.Context) error {
+	_, err := z.Broadcast(ctx, 0, "set_power", "off", "smooth", defaultZoneFade)
+	return err
+}
+
+// SetBrightness sets the brightness of every light of the zone
+func (z *Zone) SetBrightness(ctx context.Context, bright int) error {
+	_, err := z.Broadcast(ctx, 0, "set_bright", bright, "smooth", defaultZoneFade)
+	return err
+}
+
+// Transition in milliseconds of zone commands
+const defaultZoneFade = 500
+
+// zoneConfig is how a zone is persisted, lights by ID
+type zoneConfig struct {
+	Name   string              `json:"name"`
+	Kind   string              `json:"kind,omitempty"`
+	Groups map[string][]string `json:"groups,omitempty"`
+	Zones  []zoneConfig        `json:"zones,omitempty"`
+}
+
+func (z *Zone) config() zoneConfig {
+	c := zoneConfig{Name: z.Name, Kind: z.Kind}
+	for _, g := range z.Groups {
+		if c.Groups == nil {
+			c.Groups = make(map[string][]string)
+		}
+		ids := []string{}
+		for _, l := range g.Lights {
+			ids = append(ids, l.ID)
+		}
+		c.Groups[g.Name] = ids
+	}
+	for _, child := range z.Children {
+		c.Zones = append(c.Zones, child.config())
+	}
+	return c
+}
+
+// Save writes the topology under z to path as JSON
+func (z *Zone) Save(path string) error {
+	data, err := json.MarshalIndent(z.config(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// LoadZone reads the topology saved in path by Zone.Save with the
+// lights of ls, like after Lights.Load. Lights are referred by ID
+// or alias, it fails if any is not in ls
+func LoadZone(path string, ls *Lights) (*Zone, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c zoneConfig
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return c.zone(ls)
+}
+
+func (c zoneConfig) zone(ls *Lights) (*Zone, error) {
+	z := NewZone(c.Name, c.Kind)
+	for _, name := range sortedKeys(c.Groups) {
+		g := NewGroup(name)
+		for _, ref := range c.Groups[name] {
+			l := ls.Lookup(ref)
+			if l == nil {
+				return nil, fmt.Errorf("%w: zone %q unknown light %q", errInvalidParam, c.Name, ref)
+			}
+			g.Add(l)
+		}
+		z.AddGroup(g)
+	}
+	for _, cc := range c.Zones {
+		child, err := cc.zone(ls)
+		if err != nil {
+			return nil, err
+		}
+		z.Add(child)
+	}
+	return z, nil
+}